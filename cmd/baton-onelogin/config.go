@@ -3,11 +3,22 @@ package main
 import (
 	"context"
 	"fmt"
+	"regexp"
+	"strings"
+	"time"
 
+	"github.com/conductorone/baton-onelogin/pkg/connector"
+	"github.com/conductorone/baton-onelogin/pkg/expiry"
+	"github.com/conductorone/baton-onelogin/pkg/journal"
 	"github.com/conductorone/baton-sdk/pkg/cli"
+	"github.com/conductorone/baton-sdk/pkg/logging"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
+// attributeNamePattern matches user attribute names, which also name the attribute group resource types.
+var attributeNamePattern = regexp.MustCompile(`^[a-z0-9_]+$`)
+
 // config defines the external configuration required for the connector to run.
 type config struct {
 	cli.BaseConfig `mapstructure:",squash"` // Puts the base config options in the same place as the connector options
@@ -15,6 +26,77 @@ type config struct {
 	ClientId     string `mapstructure:"onelogin-client-id"`
 	ClientSecret string `mapstructure:"onelogin-client-secret"`
 	Subdomain    string `mapstructure:"subdomain"`
+
+	ProvisioningVerify        bool          `mapstructure:"provisioning-verify"`
+	ProvisioningVerifyTimeout time.Duration `mapstructure:"provisioning-verify-timeout"`
+
+	ProvisioningProtectedRoles        []string `mapstructure:"provisioning-protected-roles"`
+	ProvisioningProtectedApps         []string `mapstructure:"provisioning-protected-apps"`
+	ProvisioningProtectedEntitlements []string `mapstructure:"provisioning-protected-entitlements"`
+	ProvisioningOverrideTokenHash     string   `mapstructure:"provisioning-override-token-hash"`
+	ProvisioningOverrideToken         string   `mapstructure:"provisioning-override-token"`
+	ProvisioningMaxAdminGrants        int      `mapstructure:"provisioning-max-admin-grants"`
+	ProvisioningDryRun                bool     `mapstructure:"provisioning-dry-run"`
+
+	ProvisioningJournal     string        `mapstructure:"provisioning-journal"`
+	ProvisioningBatchWindow time.Duration `mapstructure:"provisioning-batch-window"`
+
+	ExpiringGrantsDB string        `mapstructure:"expiring-grants-db"`
+	GrantExpiry      string        `mapstructure:"grant-expiry"`
+	ExpiryInterval   time.Duration `mapstructure:"expiry-interval"`
+
+	AttributeGroups []string `mapstructure:"attribute-groups"`
+
+	AppUsageLookback time.Duration `mapstructure:"app-usage-lookback"`
+}
+
+// connectorOptions translates the optional configuration into connector options.
+func connectorOptions(ctx context.Context, cfg *config) ([]connector.Option, error) {
+	var opts []connector.Option
+
+	if cfg.ProvisioningVerify {
+		opts = append(opts, connector.WithProvisioningVerification(cfg.ProvisioningVerifyTimeout))
+	}
+
+	opts = append(opts, connector.WithProvisioningPolicy(connector.ProvisioningPolicy{
+		ProtectedRoles:        cfg.ProvisioningProtectedRoles,
+		ProtectedApps:         cfg.ProvisioningProtectedApps,
+		ProtectedEntitlements: cfg.ProvisioningProtectedEntitlements,
+		OverrideTokenHash:     cfg.ProvisioningOverrideTokenHash,
+		OverrideToken:         cfg.ProvisioningOverrideToken,
+		MaxAdminGrants:        cfg.ProvisioningMaxAdminGrants,
+		DryRun:                cfg.ProvisioningDryRun,
+	}))
+
+	if cfg.ProvisioningJournal != "" {
+		j, err := journal.Open(cfg.ProvisioningJournal)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open provisioning journal: %w", err)
+		}
+		opts = append(opts, connector.WithProvisioningJournal(j))
+	}
+
+	if cfg.ProvisioningBatchWindow > 0 {
+		opts = append(opts, connector.WithProvisioningBatching(cfg.ProvisioningBatchWindow))
+	}
+
+	if cfg.ExpiringGrantsDB != "" {
+		store, err := expiry.Open(ctx, cfg.ExpiringGrantsDB)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open expiring grants store: %w", err)
+		}
+		opts = append(opts, connector.WithGrantExpiry(store, cfg.GrantExpiry), connector.WithExpiryInterval(cfg.ExpiryInterval))
+	}
+
+	if len(cfg.AttributeGroups) > 0 {
+		opts = append(opts, connector.WithAttributeGroups(cfg.AttributeGroups...))
+	}
+
+	if cfg.AppUsageLookback > 0 {
+		opts = append(opts, connector.WithAppUsage(cfg.AppUsageLookback))
+	}
+
+	return opts, nil
 }
 
 // validateConfig is run after the configuration is loaded, and should return an error if it isn't valid.
@@ -23,6 +105,43 @@ func validateConfig(ctx context.Context, cfg *config) error {
 		return fmt.Errorf("onelogin-client-id, onelogin-client-secret and subdomain must be provided")
 	}
 
+	if cfg.ProvisioningVerify && cfg.ProvisioningVerifyTimeout <= 0 {
+		return fmt.Errorf("provisioning-verify-timeout must be positive when provisioning-verify is enabled")
+	}
+
+	if cfg.ProvisioningOverrideToken != "" && cfg.ProvisioningOverrideTokenHash == "" {
+		return fmt.Errorf("provisioning-override-token-hash must be provided to use provisioning-override-token")
+	}
+
+	if cfg.GrantExpiry != "" && cfg.ExpiringGrantsDB == "" {
+		return fmt.Errorf("expiring-grants-db must be provided to use grant-expiry")
+	}
+
+	if cfg.GrantExpiry != "" {
+		if _, err := expiry.ParseExpiry(cfg.GrantExpiry, time.Now()); err != nil {
+			return fmt.Errorf("invalid grant-expiry: %w", err)
+		}
+	}
+
+	if cfg.ProvisioningMaxAdminGrants < 0 {
+		return fmt.Errorf("provisioning-max-admin-grants must not be negative")
+	}
+
+	if cfg.AppUsageLookback < 0 {
+		return fmt.Errorf("app-usage-lookback must not be negative")
+	}
+
+	seen := make(map[string]bool)
+	for _, attribute := range cfg.AttributeGroups {
+		if !attributeNamePattern.MatchString(attribute) {
+			return fmt.Errorf("invalid attribute-groups attribute %q: only lowercase letters, digits and underscores are allowed", attribute)
+		}
+		if seen[attribute] {
+			return fmt.Errorf("attribute-groups attribute %q is listed more than once", attribute)
+		}
+		seen[attribute] = true
+	}
+
 	return nil
 }
 
@@ -31,4 +150,61 @@ func cmdFlags(cmd *cobra.Command) {
 	cmd.PersistentFlags().String("onelogin-client-id", "", "OneLogin client ID used to generate the access token. ($BATON_ONELOGIN_CLIENT_ID)")
 	cmd.PersistentFlags().String("onelogin-client-secret", "", "OneLogin client secret used to generate the access token. ($BATON_ONELOGIN_CLIENT_SECRET)")
 	cmd.PersistentFlags().String("subdomain", "", "OneLogin subdomain to connect to. ($BATON_SUBDOMAIN)")
+	cmd.PersistentFlags().Bool("provisioning-verify", false, "Wait until granted and revoked roles are visible in OneLogin before reporting success. ($BATON_PROVISIONING_VERIFY)")
+	cmd.PersistentFlags().Duration("provisioning-verify-timeout", 30*time.Second, "How long to wait for a provisioning change to become visible. ($BATON_PROVISIONING_VERIFY_TIMEOUT)")
+	cmd.PersistentFlags().StringSlice("provisioning-protected-roles", nil, "IDs or names of roles that can never be granted, revoked, renamed or deleted. ($BATON_PROVISIONING_PROTECTED_ROLES)")
+	cmd.PersistentFlags().StringSlice("provisioning-protected-apps", nil, "IDs or names of apps whose roles can never be granted or revoked. ($BATON_PROVISIONING_PROTECTED_APPS)")
+	cmd.PersistentFlags().StringSlice("provisioning-protected-entitlements", nil, "Entitlement slugs or IDs that can never be granted or revoked. ($BATON_PROVISIONING_PROTECTED_ENTITLEMENTS)")
+	cmd.PersistentFlags().String("provisioning-override-token-hash", "", "Hex encoded SHA-256 of the token that unlocks protected entitlements. ($BATON_PROVISIONING_OVERRIDE_TOKEN_HASH)")
+	cmd.PersistentFlags().String("provisioning-override-token", "", "Token that unlocks protected entitlements for this run. ($BATON_PROVISIONING_OVERRIDE_TOKEN)")
+	cmd.PersistentFlags().Int("provisioning-max-admin-grants", 0, "Maximum number of admin role grants per run, 0 for no limit. ($BATON_PROVISIONING_MAX_ADMIN_GRANTS)")
+	cmd.PersistentFlags().Bool("provisioning-dry-run", false, "Log the grants, revokes and role changes that would be made without making them in OneLogin. ($BATON_PROVISIONING_DRY_RUN)")
+	cmd.PersistentFlags().String("provisioning-journal", "", "Path of the append-only JSONL journal recording every provisioning action. ($BATON_PROVISIONING_JOURNAL)")
+	cmd.PersistentFlags().Duration("provisioning-batch-window", 0, "How long role grants and revokes wait to be batched with others for the same role, 0 to disable batching. ($BATON_PROVISIONING_BATCH_WINDOW)")
+	cmd.PersistentFlags().String("expiring-grants-db", "", "Path of the SQLite file recording role grants that expire. ($BATON_EXPIRING_GRANTS_DB)")
+	cmd.PersistentFlags().String("grant-expiry", "", "Expiry of role grants made by this run, as a duration or an RFC3339 timestamp. ($BATON_GRANT_EXPIRY)")
+	cmd.PersistentFlags().Duration("expiry-interval", 0, "How often the running connector revokes expired grants, 0 to only expire them with the expire command. ($BATON_EXPIRY_INTERVAL)")
+	cmd.PersistentFlags().StringSlice("attribute-groups", nil, "User attributes to derive synthetic groups from, e.g. department or custom_attribute_location. ($BATON_ATTRIBUTE_GROUPS)")
+	cmd.PersistentFlags().Duration("app-usage-lookback", 0, "How far back to scan app login events for the last use and use count of app access, 0 to disable. ($BATON_APP_USAGE_LOOKBACK)")
+}
+
+// loadSubcommandConfig populates and validates the config for subcommands that run outside of the connector runner.
+func loadSubcommandConfig(ctx context.Context, cmd *cobra.Command, cfg *config) (context.Context, error) {
+	ctx, err := readSubcommandConfig(ctx, cmd, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateConfig(ctx, cfg); err != nil {
+		return nil, err
+	}
+
+	return ctx, nil
+}
+
+// readSubcommandConfig reads the config from flags and BATON_ prefixed environment variables,
+// the same way the root command does, and sets up logging.
+func readSubcommandConfig(ctx context.Context, cmd *cobra.Command, cfg *config) (context.Context, error) {
+	v := viper.New()
+	v.SetEnvPrefix("baton")
+	v.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
+	v.AutomaticEnv()
+	if err := v.BindPFlags(cmd.Flags()); err != nil {
+		return nil, err
+	}
+
+	if err := v.Unmarshal(cfg); err != nil {
+		return nil, err
+	}
+
+	ctx, err := logging.Init(
+		ctx,
+		logging.WithLogFormat(v.GetString("log-format")),
+		logging.WithLogLevel(v.GetString("log-level")),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return ctx, nil
 }
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// expireCmd returns the command that revokes role grants whose expiry has passed.
+func expireCmd(ctx context.Context, cfg *config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "expire",
+		Short: "Revoke role grants whose expiry has passed",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			oneloginConnector, runCtx, err := subcommandConnector(ctx, cmd, cfg)
+			if err != nil {
+				return err
+			}
+
+			if cfg.ExpiringGrantsDB == "" {
+				return fmt.Errorf("expiring-grants-db must be provided")
+			}
+
+			report, err := oneloginConnector.ExpireGrants(runCtx)
+			if err != nil {
+				return err
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+			fmt.Fprintln(w, "STATUS\tROLE\tUSER\tENTITLEMENT\tEXPIRED AT\tERROR")
+			for _, g := range report.Revoked {
+				fmt.Fprintf(w, "revoked\t%s\t%s\t%s\t%s\t\n", g.RoleId, g.UserId, g.Entitlement, g.ExpiresAt.Format(time.RFC3339))
+			}
+			for _, g := range report.DryRun {
+				fmt.Fprintf(w, "dry run\t%s\t%s\t%s\t%s\t\n", g.RoleId, g.UserId, g.Entitlement, g.ExpiresAt.Format(time.RFC3339))
+			}
+			for _, g := range report.Failed {
+				fmt.Fprintf(w, "failed\t%s\t%s\t%s\t%s\t%s\n", g.RoleId, g.UserId, g.Entitlement, g.ExpiresAt.Format(time.RFC3339), g.LastError)
+			}
+			if err := w.Flush(); err != nil {
+				return err
+			}
+
+			if len(report.Failed) != 0 {
+				return fmt.Errorf("%d expired grants could not be revoked and will be retried", len(report.Failed))
+			}
+
+			return nil
+		},
+	}
+}
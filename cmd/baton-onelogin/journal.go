@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/conductorone/baton-onelogin/pkg/journal"
+	"github.com/spf13/cobra"
+)
+
+// journalCmd returns the command used to inspect the provisioning journal.
+func journalCmd(ctx context.Context, cfg *config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "journal",
+		Short: "Inspect the provisioning journal",
+	}
+
+	verifyCmd := &cobra.Command{
+		Use:   "verify [path]",
+		Short: "Verify the hash chain of the provisioning journal",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, err := readSubcommandConfig(ctx, cmd, cfg)
+			if err != nil {
+				return err
+			}
+
+			path := cfg.ProvisioningJournal
+			if len(args) == 1 {
+				path = args[0]
+			}
+			if path == "" {
+				return fmt.Errorf("journal path must be provided as an argument or with provisioning-journal")
+			}
+
+			count, err := journal.Verify(path)
+			if err != nil {
+				return fmt.Errorf("journal verification failed: %w", err)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "journal %s verified, %d entries\n", path, count)
+			return nil
+		},
+	}
+
+	cmd.AddCommand(verifyCmd)
+
+	return cmd
+}
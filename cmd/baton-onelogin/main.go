@@ -27,6 +27,7 @@ func main() {
 
 	cmd.Version = version
 	cmdFlags(cmd)
+	cmd.AddCommand(rolesCmd(ctx, cfg), journalCmd(ctx, cfg), expireCmd(ctx, cfg))
 
 	err = cmd.Execute()
 	if err != nil {
@@ -38,7 +39,13 @@ func main() {
 func getConnector(ctx context.Context, cfg *config) (types.ConnectorServer, error) {
 	l := ctxzap.Extract(ctx)
 
-	oneloginConnector, err := connector.New(ctx, cfg.ClientId, cfg.ClientSecret, cfg.Subdomain)
+	opts, err := connectorOptions(ctx, cfg)
+	if err != nil {
+		l.Error("error creating connector", zap.Error(err))
+		return nil, err
+	}
+
+	oneloginConnector, err := connector.New(ctx, cfg.ClientId, cfg.ClientSecret, cfg.Subdomain, opts...)
 	if err != nil {
 		l.Error("error creating connector", zap.Error(err))
 		return nil, err
@@ -50,5 +57,5 @@ func getConnector(ctx context.Context, cfg *config) (types.ConnectorServer, erro
 		return nil, err
 	}
 
-	return c, nil
+	return connector.WrapConnectorServer(c, oneloginConnector), nil
 }
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/conductorone/baton-onelogin/pkg/connector"
+	"github.com/conductorone/baton-onelogin/pkg/onelogin"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/spf13/cobra"
+)
+
+// rolesCmd returns the command used to manage the lifecycle of OneLogin roles.
+func rolesCmd(ctx context.Context, cfg *config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "roles",
+		Short: "Create, rename and delete OneLogin roles",
+	}
+
+	createCmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a OneLogin role",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			oneloginConnector, runCtx, err := subcommandConnector(ctx, cmd, cfg)
+			if err != nil {
+				return err
+			}
+
+			name, _ := cmd.Flags().GetString("name")
+			apps, _ := cmd.Flags().GetIntSlice("app-ids")
+			users, _ := cmd.Flags().GetIntSlice("user-ids")
+			admins, _ := cmd.Flags().GetIntSlice("admin-ids")
+
+			role, err := oneloginConnector.CreateRole(runCtx, &onelogin.RoleBody{
+				Name:   name,
+				Apps:   apps,
+				Users:  users,
+				Admins: admins,
+			})
+			if err != nil {
+				return err
+			}
+
+			if cfg.ProvisioningDryRun {
+				fmt.Fprintf(cmd.OutOrStdout(), "dry run: would create role %s\n", role.DisplayName)
+				return nil
+			}
+
+			printRole(cmd, role)
+			return nil
+		},
+	}
+	createCmd.Flags().String("name", "", "Name of the role to create")
+	createCmd.Flags().IntSlice("app-ids", nil, "IDs of the apps initially assigned to the role")
+	createCmd.Flags().IntSlice("user-ids", nil, "IDs of the users initially assigned to the role")
+	createCmd.Flags().IntSlice("admin-ids", nil, "IDs of the users initially administering the role")
+	_ = createCmd.MarkFlagRequired("name")
+
+	renameCmd := &cobra.Command{
+		Use:   "rename",
+		Short: "Rename a OneLogin role",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			oneloginConnector, runCtx, err := subcommandConnector(ctx, cmd, cfg)
+			if err != nil {
+				return err
+			}
+
+			roleId, _ := cmd.Flags().GetString("role-id")
+			name, _ := cmd.Flags().GetString("name")
+
+			role, err := oneloginConnector.RenameRole(runCtx, roleId, name)
+			if err != nil {
+				return err
+			}
+
+			if cfg.ProvisioningDryRun {
+				fmt.Fprintf(cmd.OutOrStdout(), "dry run: would rename role %s to %s\n", roleId, role.DisplayName)
+				return nil
+			}
+
+			printRole(cmd, role)
+			return nil
+		},
+	}
+	renameCmd.Flags().String("role-id", "", "ID of the role to rename")
+	renameCmd.Flags().String("name", "", "New name of the role")
+	_ = renameCmd.MarkFlagRequired("role-id")
+	_ = renameCmd.MarkFlagRequired("name")
+
+	deleteCmd := &cobra.Command{
+		Use:   "delete",
+		Short: "Delete a OneLogin role",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			oneloginConnector, runCtx, err := subcommandConnector(ctx, cmd, cfg)
+			if err != nil {
+				return err
+			}
+
+			roleId, _ := cmd.Flags().GetString("role-id")
+			force, _ := cmd.Flags().GetBool("force")
+
+			err = oneloginConnector.DeleteRole(runCtx, roleId, force)
+			if err != nil {
+				return err
+			}
+
+			if cfg.ProvisioningDryRun {
+				fmt.Fprintf(cmd.OutOrStdout(), "dry run: would delete role %s\n", roleId)
+				return nil
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "deleted role %s\n", roleId)
+			return nil
+		},
+	}
+	deleteCmd.Flags().String("role-id", "", "ID of the role to delete")
+	deleteCmd.Flags().Bool("force", false, "Delete the role even if it still has apps attached")
+	_ = deleteCmd.MarkFlagRequired("role-id")
+
+	cmd.AddCommand(createCmd, renameCmd, deleteCmd)
+
+	return cmd
+}
+
+// subcommandConnector loads the configuration and builds the OneLogin connector for a subcommand.
+func subcommandConnector(ctx context.Context, cmd *cobra.Command, cfg *config) (*connector.OneLogin, context.Context, error) {
+	runCtx, err := loadSubcommandConfig(ctx, cmd, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	opts, err := connectorOptions(runCtx, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	oneloginConnector, err := connector.New(runCtx, cfg.ClientId, cfg.ClientSecret, cfg.Subdomain, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return oneloginConnector, runCtx, nil
+}
+
+func printRole(cmd *cobra.Command, role *v2.Resource) {
+	fmt.Fprintf(cmd.OutOrStdout(), "%s\t%s\n", role.Id.Resource, role.DisplayName)
+}
@@ -0,0 +1,352 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/conductorone/baton-onelogin/pkg/onelogin"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	ent "github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+	rs "github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+const apiScopeEntitlementPrefix = "scope:"
+
+type apiAuthorizationResourceType struct {
+	resourceType *v2.ResourceType
+	client       *onelogin.Client
+	provisioning *provisioning
+
+	// scope changes are read-modify-writes of the scope list of a client app, so they are serialized per client
+	clientLocksMutex sync.Mutex
+	clientLocks      map[string]*sync.Mutex
+}
+
+func (a *apiAuthorizationResourceType) ResourceType(_ context.Context) *v2.ResourceType {
+	return a.resourceType
+}
+
+// Create a new connector resource for an OneLogin API authorization server.
+func apiAuthorizationResource(authorization *onelogin.APIAuthorization) (*v2.Resource, error) {
+	profile := map[string]interface{}{
+		"api_authorization_id":             authorization.Id,
+		"api_authorization_name":           authorization.Name,
+		"api_authorization_description":    authorization.Description,
+		"resource_identifier":              authorization.Configuration.ResourceIdentifier,
+		"audiences":                        stringsToInterfaces(authorization.Configuration.Audiences),
+		"access_token_expiration_minutes":  authorization.Configuration.AccessTokenExpirationMinutes,
+		"refresh_token_expiration_minutes": authorization.Configuration.RefreshTokenExpirationMinutes,
+	}
+
+	authorizationTraitOptions := []rs.AppTraitOption{
+		rs.WithAppProfile(profile),
+	}
+
+	resource, err := rs.NewAppResource(
+		authorization.Name,
+		resourceTypeAPIAuthorization,
+		authorization.Id,
+		authorizationTraitOptions,
+		rs.WithDescription(authorization.Description),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return resource, nil
+}
+
+func apiScopeEntitlementSlug(scopeId int) string {
+	return apiScopeEntitlementPrefix + strconv.Itoa(scopeId)
+}
+
+func (a *apiAuthorizationResourceType) List(ctx context.Context, _ *v2.ResourceId, _ *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	authorizations, err := a.client.GetAPIAuthorizations(ctx)
+	if err != nil {
+		if skipUnavailable(ctx, err, "api authorizations") {
+			return nil, "", nil, nil
+		}
+		return nil, "", nil, fmt.Errorf("onelogin-connector: failed to list api authorizations: %w", err)
+	}
+
+	var rv []*v2.Resource
+	for _, authorization := range authorizations {
+		authorizationCopy := authorization
+		ar, err := apiAuthorizationResource(&authorizationCopy)
+
+		if err != nil {
+			return nil, "", nil, err
+		}
+		addExternalLink(a.client, ar)
+
+		rv = append(rv, ar)
+	}
+
+	return rv, "", nil, nil
+}
+
+func (a *apiAuthorizationResourceType) Entitlements(ctx context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	scopes, err := a.client.GetAPIScopes(ctx, resource.Id.Resource)
+	if err != nil {
+		if skipUnavailable(ctx, err, "api authorization scopes") {
+			return nil, "", nil, nil
+		}
+		return nil, "", nil, fmt.Errorf("onelogin-connector: failed to list scopes of api authorization %s: %w", resource.Id.Resource, err)
+	}
+
+	var rv []*v2.Entitlement
+	for _, scope := range scopes {
+		scopeOptions := []ent.EntitlementOption{
+			ent.WithGrantableTo(resourceTypeApp),
+			ent.WithDisplayName(fmt.Sprintf("%s Scope %s", resource.DisplayName, scope.Value)),
+			ent.WithDescription(scope.Description),
+		}
+
+		rv = append(
+			rv,
+			ent.NewPermissionEntitlement(
+				resource,
+				apiScopeEntitlementSlug(scope.Id),
+				scopeOptions...,
+			),
+		)
+	}
+
+	return rv, "", nil, nil
+}
+
+func (a *apiAuthorizationResourceType) Grants(ctx context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	clients, err := a.client.GetAPIClients(ctx, resource.Id.Resource)
+	if err != nil {
+		if skipUnavailable(ctx, err, "api authorization clients") {
+			return nil, "", nil, nil
+		}
+		return nil, "", nil, fmt.Errorf("onelogin-connector: failed to list clients of api authorization %s: %w", resource.Id.Resource, err)
+	}
+
+	var rv []*v2.Grant
+	for _, client := range clients {
+		appResource := &v2.ResourceId{
+			ResourceType: resourceTypeApp.Id,
+			Resource:     strconv.Itoa(client.AppId),
+		}
+
+		for _, scope := range client.Scopes {
+			rv = append(
+				rv,
+				grant.NewGrant(
+					resource,
+					apiScopeEntitlementSlug(scope.Id),
+					appResource,
+				),
+			)
+		}
+	}
+
+	return rv, "", nil, nil
+}
+
+func (a *apiAuthorizationResourceType) Grant(ctx context.Context, principal *v2.Resource, entitlement *v2.Entitlement) (annotations.Annotations, error) {
+	recorder := &onelogin.ResponseRecorder{}
+	annos, err := a.grant(onelogin.WithResponseRecorder(ctx, recorder), principal, entitlement)
+	a.provisioning.record(ctx, "grant", principal.Id, entitlement.Id, entitlement.Resource.Id, recorder, annos, err)
+
+	return annos, err
+}
+
+func (a *apiAuthorizationResourceType) grant(ctx context.Context, principal *v2.Resource, entitlement *v2.Entitlement) (annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	authorizationId := entitlement.Resource.Id.Resource
+	appId, scopeId, err := apiClientScope(principal.Id, entitlement.Slug)
+	if err != nil {
+		return nil, err
+	}
+
+	unlock := a.lockClient(authorizationId, appId)
+	defer unlock()
+
+	client, err := a.findClient(ctx, authorizationId, appId)
+	if err != nil {
+		return nil, err
+	}
+
+	scopeIds := apiClientScopeIds(client)
+	for _, id := range scopeIds {
+		if id == scopeId {
+			return provisioningAnnotations(map[string]interface{}{grantAlreadyExists: true})
+		}
+	}
+
+	err = a.provisioning.checkPolicy(ctx, a.client, entitlement)
+	if err != nil {
+		return nil, fmt.Errorf("onelogin-connector: refusing to grant scope %d of api authorization %s: %w", scopeId, authorizationId, err)
+	}
+
+	if a.provisioning.policy.DryRun {
+		l.Info(
+			"onelogin-connector: dry run, would grant api scope",
+			zap.String("api_authorization_id", authorizationId),
+			zap.Int("scope_id", scopeId),
+			zap.Int("app_id", appId),
+		)
+		return provisioningAnnotations(dryRunMetadata("grant"))
+	}
+
+	if client == nil {
+		err = a.client.AddAPIClient(ctx, authorizationId, appId, []int{scopeId})
+	} else {
+		err = a.client.UpdateAPIClientScopes(ctx, authorizationId, appId, append(scopeIds, scopeId))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("onelogin-connector: failed to grant scope %d of api authorization %s: %w", scopeId, authorizationId, err)
+	}
+
+	return nil, nil
+}
+
+func (a *apiAuthorizationResourceType) Revoke(ctx context.Context, grant *v2.Grant) (annotations.Annotations, error) {
+	recorder := &onelogin.ResponseRecorder{}
+	annos, err := a.revoke(onelogin.WithResponseRecorder(ctx, recorder), grant)
+	a.provisioning.record(ctx, "revoke", grant.Principal.Id, grant.Entitlement.Id, grant.Entitlement.Resource.Id, recorder, annos, err)
+
+	return annos, err
+}
+
+// revoke removes the scope from the client app. A client left without scopes stays registered with
+// the authorization server, with an empty scope list.
+func (a *apiAuthorizationResourceType) revoke(ctx context.Context, grant *v2.Grant) (annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	authorizationId := grant.Entitlement.Resource.Id.Resource
+	appId, scopeId, err := apiClientScope(grant.Principal.Id, grant.Entitlement.Slug)
+	if err != nil {
+		return nil, err
+	}
+
+	unlock := a.lockClient(authorizationId, appId)
+	defer unlock()
+
+	client, err := a.findClient(ctx, authorizationId, appId)
+	if err != nil {
+		return nil, err
+	}
+
+	remaining := []int{}
+	held := false
+	for _, id := range apiClientScopeIds(client) {
+		if id == scopeId {
+			held = true
+			continue
+		}
+		remaining = append(remaining, id)
+	}
+
+	if !held {
+		return provisioningAnnotations(map[string]interface{}{grantAlreadyRevoked: true})
+	}
+
+	err = a.provisioning.checkPolicy(ctx, a.client, grant.Entitlement)
+	if err != nil {
+		return nil, fmt.Errorf("onelogin-connector: refusing to revoke scope %d of api authorization %s: %w", scopeId, authorizationId, err)
+	}
+
+	if a.provisioning.policy.DryRun {
+		l.Info(
+			"onelogin-connector: dry run, would revoke api scope",
+			zap.String("api_authorization_id", authorizationId),
+			zap.Int("scope_id", scopeId),
+			zap.Int("app_id", appId),
+		)
+		return provisioningAnnotations(dryRunMetadata("revoke"))
+	}
+
+	err = a.client.UpdateAPIClientScopes(ctx, authorizationId, appId, remaining)
+	if err != nil {
+		return nil, fmt.Errorf("onelogin-connector: failed to revoke scope %d of api authorization %s: %w", scopeId, authorizationId, err)
+	}
+
+	return nil, nil
+}
+
+// lockClient locks the scope list of the client app of the authorization server and returns the unlock function.
+func (a *apiAuthorizationResourceType) lockClient(authorizationId string, appId int) func() {
+	key := fmt.Sprintf("%s:%d", authorizationId, appId)
+
+	a.clientLocksMutex.Lock()
+	lock, ok := a.clientLocks[key]
+	if !ok {
+		lock = &sync.Mutex{}
+		a.clientLocks[key] = lock
+	}
+	a.clientLocksMutex.Unlock()
+
+	lock.Lock()
+	return lock.Unlock
+}
+
+// findClient returns the client app of the authorization server, or nil if the app is not a client.
+func (a *apiAuthorizationResourceType) findClient(ctx context.Context, authorizationId string, appId int) (*onelogin.APIClient, error) {
+	clients, err := a.client.GetAPIClients(ctx, authorizationId)
+	if err != nil {
+		return nil, fmt.Errorf("onelogin-connector: failed to list clients of api authorization %s: %w", authorizationId, err)
+	}
+
+	for i := range clients {
+		if clients[i].AppId == appId {
+			return &clients[i], nil
+		}
+	}
+
+	return nil, nil
+}
+
+func apiClientScopeIds(client *onelogin.APIClient) []int {
+	if client == nil {
+		return nil
+	}
+
+	rv := make([]int, 0, len(client.Scopes))
+	for _, scope := range client.Scopes {
+		rv = append(rv, scope.Id)
+	}
+
+	return rv
+}
+
+// apiClientScope returns the app and scope IDs a scope grant or revoke applies to.
+func apiClientScope(principal *v2.ResourceId, slug string) (int, int, error) {
+	if principal.ResourceType != resourceTypeApp.Id {
+		return 0, 0, fmt.Errorf("onelogin-connector: only apps can be granted api scopes, got %s", principal.ResourceType)
+	}
+
+	appId, err := strconv.Atoi(principal.Resource)
+	if err != nil {
+		return 0, 0, fmt.Errorf("onelogin-connector: invalid app id %s: %w", principal.Resource, err)
+	}
+
+	scopeId, err := strconv.Atoi(strings.TrimPrefix(slug, apiScopeEntitlementPrefix))
+	if err != nil {
+		return 0, 0, fmt.Errorf("onelogin-connector: invalid api scope entitlement %s: %w", slug, err)
+	}
+
+	return appId, scopeId, nil
+}
+
+func apiAuthorizationBuilder(client *onelogin.Client, provisioning *provisioning) *apiAuthorizationResourceType {
+	return &apiAuthorizationResourceType{
+		resourceType: resourceTypeAPIAuthorization,
+		client:       client,
+		provisioning: provisioning,
+		clientLocks:  make(map[string]*sync.Mutex),
+	}
+}
@@ -0,0 +1,399 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/conductorone/baton-onelogin/pkg/onelogin"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	ent "github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+)
+
+const (
+	appParameterEntitlementPrefix = "parameter"
+	appParameterResourceId        = "app-parameter"
+
+	// user_attribute_mappings value of parameters built from a macro, which cannot be resolved per user.
+	appParameterMacro = "_macro_"
+	// attributes_transformations value of parameters splitting a semicolon delimited input into values.
+	appParameterSemicolonDelimited = "semicolon_delimited"
+
+	// Parameters are read in both the entitlements and the grants of an app, so they are kept for the sync.
+	appParametersCacheTTL = time.Hour
+)
+
+// App rules set a parameter through an action named after the parameter key.
+const appRuleActionSetPrefix = "set_"
+
+// Operators and match type of the app rule conditions evaluated against user attributes.
+const (
+	ruleOperatorEquals      = "="
+	ruleOperatorNotEquals   = "!="
+	ruleOperatorContains    = "~"
+	ruleOperatorNotContains = "!~"
+
+	ruleMatchAny = "any"
+)
+
+// ruleConditionAttributes names the user attribute holding a rule condition source, where the two differ.
+var ruleConditionAttributes = map[string]string{
+	"member_of": "group_id",
+}
+
+// appParameter is a multi-value app parameter along with the values it can take
+// and the app users holding each value.
+type appParameter struct {
+	key       string
+	parameter onelogin.AppParameter
+	values    []string
+	holders   map[string][]int
+}
+
+type appParametersCacheEntry struct {
+	parameters []*appParameter
+	timestamp  time.Time
+}
+
+// appParametersCache keeps the resolved parameters of each app for the sync.
+type appParametersCache struct {
+	mutex sync.Mutex
+	apps  map[string]appParametersCacheEntry
+}
+
+func newAppParametersCache() *appParametersCache {
+	return &appParametersCache{apps: make(map[string]appParametersCacheEntry)}
+}
+
+func (c *appParametersCache) get(appId string) ([]*appParameter, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	entry, ok := c.apps[appId]
+	if !ok || time.Since(entry.timestamp) >= appParametersCacheTTL {
+		return nil, false
+	}
+
+	return entry.parameters, true
+}
+
+func (c *appParametersCache) put(appId string, parameters []*appParameter) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.apps[appId] = appParametersCacheEntry{
+		parameters: parameters,
+		timestamp:  time.Now(),
+	}
+}
+
+func (p *appParameter) label() string {
+	if p.parameter.Label != "" {
+		return p.parameter.Label
+	}
+
+	return p.key
+}
+
+func appParameterEntitlementSlug(key, value string) string {
+	return fmt.Sprintf("%s:%s:%s", appParameterEntitlementPrefix, key, value)
+}
+
+// isMultiValueAppParameter reports whether a parameter carries a list of values, such as
+// provisioned entitlements or a semicolon delimited attribute.
+func isMultiValueAppParameter(parameter onelogin.AppParameter) bool {
+	return parameter.ProvisionedEntitlements || parameter.AttributesTransformations == appParameterSemicolonDelimited
+}
+
+// splitAppParameterValues splits a parameter value list, delimited by semicolons or new lines.
+func splitAppParameterValues(value string) []string {
+	var rv []string
+	for _, v := range strings.FieldsFunc(value, func(r rune) bool { return r == ';' || r == '\n' }) {
+		if v = strings.TrimSpace(v); v != "" {
+			rv = append(rv, v)
+		}
+	}
+
+	return rv
+}
+
+// appParameters returns the multi-value parameters of an app, with the values held by each app user.
+// A value is held by the user when the user attribute the parameter is mapped to holds it, which covers
+// values set on the user directly or through mappings, or when an enabled app rule matching the user sets it.
+func (a *appResourceType) appParameters(ctx context.Context, appId string) ([]*appParameter, error) {
+	if cached, ok := a.parameters.get(appId); ok {
+		return cached, nil
+	}
+
+	rv, err := a.resolveAppParameters(ctx, appId)
+	if err != nil {
+		return nil, err
+	}
+	a.parameters.put(appId, rv)
+
+	return rv, nil
+}
+
+func (a *appResourceType) resolveAppParameters(ctx context.Context, appId string) ([]*appParameter, error) {
+	app, err := a.client.GetApp(ctx, appId)
+	if err != nil {
+		return nil, fmt.Errorf("onelogin-connector: failed to get app %s: %w", appId, err)
+	}
+
+	var rv []*appParameter
+	for key, parameter := range app.Parameters {
+		if !isMultiValueAppParameter(parameter) {
+			continue
+		}
+
+		rv = append(rv, &appParameter{
+			key:       key,
+			parameter: parameter,
+			holders:   make(map[string][]int),
+		})
+	}
+	if len(rv) == 0 {
+		return []*appParameter{}, nil
+	}
+	sort.Slice(rv, func(i, j int) bool { return rv[i].key < rv[j].key })
+
+	rules, err := a.client.GetAppRules(ctx, appId)
+	if err != nil {
+		return nil, fmt.Errorf("onelogin-connector: failed to list rules of app %s: %w", appId, err)
+	}
+
+	userIds, err := a.appUserIds(ctx, appId)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range rv {
+		values := make(map[string]bool)
+		for _, value := range splitAppParameterValues(p.parameter.Values + "\n" + p.parameter.DefaultValues) {
+			values[value] = true
+		}
+
+		ruleValues := appRuleParameterValues(rules, p.key)
+		for _, rule := range ruleValues {
+			for _, value := range rule.values {
+				values[value] = true
+			}
+		}
+
+		attribute := p.parameter.UserAttributeMappings
+		if attribute == appParameterMacro {
+			attribute = ""
+		}
+
+		for _, userId := range userIds {
+			user, err := a.userAttributes.get(ctx, userId)
+			if err != nil {
+				return nil, err
+			}
+			if user == nil {
+				continue
+			}
+
+			held := make(map[string]bool)
+			if attribute != "" {
+				for _, value := range splitAppParameterValues(user.Value(attribute)) {
+					held[value] = true
+				}
+			}
+			for _, rule := range ruleValues {
+				if ruleMatches(rule.rule, user) {
+					for _, value := range rule.values {
+						held[value] = true
+					}
+				}
+			}
+
+			for value := range held {
+				values[value] = true
+				p.holders[value] = append(p.holders[value], userId)
+			}
+		}
+
+		for value := range values {
+			p.values = append(p.values, value)
+		}
+		sort.Strings(p.values)
+	}
+
+	return rv, nil
+}
+
+type appRuleValues struct {
+	rule   onelogin.AppRule
+	values []string
+}
+
+// appRuleParameterValues returns the enabled app rules setting the parameter, with the values they set.
+func appRuleParameterValues(rules []onelogin.AppRule, key string) []appRuleValues {
+	var rv []appRuleValues
+	for _, rule := range rules {
+		if !rule.Enabled {
+			continue
+		}
+
+		var values []string
+		for _, action := range rule.Actions {
+			if action.Action != appRuleActionSetPrefix+key {
+				continue
+			}
+			for _, value := range action.Value {
+				values = append(values, splitAppParameterValues(value)...)
+			}
+		}
+		if len(values) > 0 {
+			rv = append(rv, appRuleValues{rule: rule, values: values})
+		}
+	}
+
+	return rv
+}
+
+// ruleMatches reports whether the app rule applies to the user. Rules with a condition that cannot be
+// evaluated against user attributes, such as login history, are never considered a match, so no value
+// is granted from them on a guess.
+func ruleMatches(rule onelogin.AppRule, user onelogin.UserAttributes) bool {
+	if len(rule.Conditions) == 0 {
+		return true
+	}
+
+	matchAny := rule.Match == ruleMatchAny
+	for _, condition := range rule.Conditions {
+		matched, ok := ruleConditionMatches(condition, user)
+		if !ok {
+			return false
+		}
+		if matched && matchAny {
+			return true
+		}
+		if !matched && !matchAny {
+			return false
+		}
+	}
+
+	return !matchAny
+}
+
+// ruleConditionMatches evaluates a rule condition against a user attribute. The second result is
+// false if the condition source is not a user attribute or its operator is not supported.
+func ruleConditionMatches(condition onelogin.RuleCondition, user onelogin.UserAttributes) (bool, bool) {
+	attribute := condition.Source
+	if name, ok := ruleConditionAttributes[attribute]; ok {
+		attribute = name
+	}
+	if !user.Has(attribute) {
+		return false, false
+	}
+
+	value := user.Value(attribute)
+	switch condition.Operator {
+	case ruleOperatorEquals:
+		return strings.EqualFold(value, condition.Value), true
+	case ruleOperatorNotEquals:
+		return !strings.EqualFold(value, condition.Value), true
+	case ruleOperatorContains:
+		return strings.Contains(strings.ToLower(value), strings.ToLower(condition.Value)), true
+	case ruleOperatorNotContains:
+		return !strings.Contains(strings.ToLower(value), strings.ToLower(condition.Value)), true
+	default:
+		return false, false
+	}
+}
+
+func (a *appResourceType) appUserIds(ctx context.Context, appId string) ([]int, error) {
+	var rv []int
+	cursor := ""
+
+	for {
+		appUsers, nextCursor, err := a.client.GetAppUsers(ctx, appId, onelogin.PaginationVars{
+			Limit:  ResourcesPageSize,
+			Cursor: cursor,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("onelogin-connector: failed to list app users: %w", err)
+		}
+
+		for _, user := range appUsers {
+			rv = append(rv, user.Id)
+		}
+		if nextCursor == "" {
+			break
+		}
+		cursor = nextCursor
+	}
+
+	return rv, nil
+}
+
+func (a *appResourceType) appParameterEntitlements(ctx context.Context, resource *v2.Resource) ([]*v2.Entitlement, error) {
+	parameters, err := a.appParameters(ctx, resource.Id.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	var rv []*v2.Entitlement
+	for _, p := range parameters {
+		for _, value := range p.values {
+			options := []ent.EntitlementOption{
+				ent.WithGrantableTo(resourceTypeUser),
+				ent.WithDisplayName(fmt.Sprintf("%s: %s", resource.DisplayName, value)),
+				ent.WithDescription(fmt.Sprintf("%s set to %s in %s app in OneLogin", p.label(), value, resource.DisplayName)),
+			}
+
+			rv = append(
+				rv,
+				ent.NewPermissionEntitlement(
+					resource,
+					appParameterEntitlementSlug(p.key, value),
+					options...,
+				),
+			)
+		}
+	}
+
+	return rv, nil
+}
+
+func (a *appResourceType) appParameterGrants(ctx context.Context, resource *v2.Resource) ([]*v2.Grant, error) {
+	parameters, err := a.appParameters(ctx, resource.Id.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	var rv []*v2.Grant
+	for _, p := range parameters {
+		for _, value := range p.values {
+			for _, userId := range p.holders[value] {
+				userResource := &v2.ResourceId{
+					ResourceType: resourceTypeUser.Id,
+					Resource:     strconv.Itoa(userId),
+				}
+
+				rv = append(
+					rv,
+					grant.NewGrant(
+						resource,
+						appParameterEntitlementSlug(p.key, value),
+						userResource,
+						grant.WithGrantMetadata(map[string]interface{}{
+							"parameter":       p.key,
+							"parameter_label": p.label(),
+							"user_attribute":  p.parameter.UserAttributeMappings,
+						}),
+					),
+				)
+			}
+		}
+	}
+
+	return rv, nil
+}
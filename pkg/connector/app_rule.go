@@ -0,0 +1,94 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/conductorone/baton-onelogin/pkg/onelogin"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	rs "github.com/conductorone/baton-sdk/pkg/types/resource"
+)
+
+type appRuleResourceType struct {
+	resourceType *v2.ResourceType
+	client       *onelogin.Client
+}
+
+func (a *appRuleResourceType) ResourceType(_ context.Context) *v2.ResourceType {
+	return a.resourceType
+}
+
+// Create a new connector resource for an OneLogin app rule, nested under its app.
+func appRuleResource(rule *onelogin.AppRule, parentResourceID *v2.ResourceId) (*v2.Resource, error) {
+	profile := map[string]interface{}{
+		"rule_id":      rule.Id,
+		"rule_name":    rule.Name,
+		"rule_match":   rule.Match,
+		"rule_enabled": rule.Enabled,
+		"app_id":       parentResourceID.Resource,
+		"conditions":   ruleConditionsProfile(rule.Conditions),
+		"actions":      ruleActionsProfile(rule.Actions),
+	}
+	if rule.Position != nil {
+		profile["rule_position"] = *rule.Position
+	}
+
+	resource, err := inventoryResource(
+		rule.Name,
+		resourceTypeAppRule,
+		rule.Id,
+		profile,
+		rs.WithParentResourceID(parentResourceID),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return resource, nil
+}
+
+func (a *appRuleResourceType) List(ctx context.Context, parentResourceID *v2.ResourceId, _ *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	// app rules only exist under an app
+	if parentResourceID == nil {
+		return nil, "", nil, nil
+	}
+
+	rules, err := a.client.GetAppRules(ctx, parentResourceID.Resource)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("onelogin-connector: failed to list rules of app %s: %w", parentResourceID.Resource, err)
+	}
+
+	var rv []*v2.Resource
+	for _, rule := range rules {
+		ruleCopy := rule
+		rr, err := appRuleResource(&ruleCopy, parentResourceID)
+
+		if err != nil {
+			return nil, "", nil, err
+		}
+		addExternalLink(a.client, rr)
+
+		rv = append(rv, rr)
+	}
+
+	return rv, "", nil, nil
+}
+
+// App rules drive provisioning rather than grant access, so they have no entitlements or grants of their own.
+func (a *appRuleResourceType) Entitlements(_ context.Context, _ *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	return nil, "", nil, nil
+}
+
+func (a *appRuleResourceType) Grants(_ context.Context, _ *v2.Resource, _ *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	return nil, "", nil, nil
+}
+
+func appRuleBuilder(client *onelogin.Client) *appRuleResourceType {
+	return &appRuleResourceType{
+		resourceType: resourceTypeAppRule,
+		client:       client,
+	}
+}
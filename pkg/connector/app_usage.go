@@ -0,0 +1,197 @@
+package connector
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/conductorone/baton-onelogin/pkg/onelogin"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+const (
+	appLoginEventType = "USER_LOGGED_INTO_APP"
+	// ID of the app login event type, used when the event type catalog does not list it by name.
+	appLoginEventTypeId = 8
+
+	// Scanning the event log is expensive, so app usage is only reloaded once per sync in practice.
+	appUsageCacheTTL = time.Hour
+)
+
+// Keys of the app profile statistics and grant metadata describing app usage.
+const (
+	appUsageLastUsed    = "last_used_at"
+	appUsageCount       = "use_count"
+	appUsageActiveUsers = "active_users"
+	appUsageWindowStart = "usage_window_start"
+)
+
+// appUsage counts the logins into an app, by one user or by all of them, within the lookback window.
+type appUsage struct {
+	lastUsed    time.Time
+	count       int
+	activeUsers int
+	windowStart time.Time
+}
+
+func (u *appUsage) record(at time.Time) {
+	u.count++
+	if at.After(u.lastUsed) {
+		u.lastUsed = at
+	}
+}
+
+// metadata returns the usage as grant metadata, or as app profile statistics along with the number of active users.
+func (u *appUsage) metadata(withActiveUsers bool) map[string]interface{} {
+	rv := map[string]interface{}{
+		appUsageCount:       u.count,
+		appUsageWindowStart: u.windowStart.UTC().Format(time.RFC3339),
+	}
+	if !u.lastUsed.IsZero() {
+		rv[appUsageLastUsed] = u.lastUsed.UTC().Format(time.RFC3339)
+	}
+	if withActiveUsers {
+		rv[appUsageActiveUsers] = u.activeUsers
+	}
+
+	return rv
+}
+
+// appUsageIndex caches the app logins found in the event log over the lookback window, by app and user.
+// A nil index means usage tracking is disabled.
+type appUsageIndex struct {
+	client      *onelogin.Client
+	lookback    time.Duration
+	mutex       sync.Mutex
+	apps        map[string]*appUsage
+	users       map[string]map[string]*appUsage
+	windowStart time.Time
+	timestamp   time.Time
+}
+
+func newAppUsageIndex(client *onelogin.Client, lookback time.Duration) *appUsageIndex {
+	if lookback <= 0 {
+		return nil
+	}
+
+	return &appUsageIndex{
+		client:   client,
+		lookback: lookback,
+	}
+}
+
+// refresh rescans the event log if the TTL expired. The caller must hold the mutex. Usage is optional,
+// so a failed scan is logged and leaves the apps without usage until the next refresh.
+func (a *appUsageIndex) refresh(ctx context.Context) {
+	if a.apps != nil && time.Since(a.timestamp) < appUsageCacheTTL {
+		return
+	}
+
+	l := ctxzap.Extract(ctx)
+
+	now := time.Now()
+	windowStart := now.Add(-a.lookback)
+	apps := make(map[string]*appUsage)
+	users := make(map[string]map[string]*appUsage)
+
+	eventTypeIds, err := a.appLoginEventTypeIds(ctx)
+	if err == nil {
+		events := a.client.NewEventIterator(onelogin.EventFilterVars{
+			Since:        windowStart,
+			EventTypeIds: eventTypeIds,
+		}, "", ResourcesPageSize)
+
+		for events.Next(ctx) {
+			event := events.Event()
+			if event.AppId == 0 || event.UserId == 0 {
+				continue
+			}
+
+			appId := strconv.Itoa(event.AppId)
+			userId := strconv.Itoa(event.UserId)
+
+			if apps[appId] == nil {
+				apps[appId] = &appUsage{windowStart: windowStart}
+				users[appId] = make(map[string]*appUsage)
+			}
+			if users[appId][userId] == nil {
+				users[appId][userId] = &appUsage{windowStart: windowStart}
+				apps[appId].activeUsers++
+			}
+
+			apps[appId].record(event.CreatedAt)
+			users[appId][userId].record(event.CreatedAt)
+		}
+		err = events.Err()
+	}
+
+	a.timestamp = now
+	if err != nil {
+		l.Warn("onelogin-connector: failed to scan app logins, app usage is not available", zap.Error(err))
+		a.apps = map[string]*appUsage{}
+		a.users = map[string]map[string]*appUsage{}
+		a.windowStart = time.Time{}
+		return
+	}
+
+	a.apps = apps
+	a.users = users
+	a.windowStart = windowStart
+}
+
+func (a *appUsageIndex) appLoginEventTypeIds(ctx context.Context) ([]int, error) {
+	eventTypes, err := a.client.GetEventTypes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if ids := eventTypes.Ids(appLoginEventType); len(ids) > 0 {
+		return ids, nil
+	}
+
+	return []int{appLoginEventTypeId}, nil
+}
+
+// app returns the logins into the app by all users, or nil if usage is not available.
+func (a *appUsageIndex) app(ctx context.Context, appId string) *appUsage {
+	if a == nil {
+		return nil
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.refresh(ctx)
+	if a.windowStart.IsZero() {
+		return nil
+	}
+
+	if usage, ok := a.apps[appId]; ok {
+		return usage
+	}
+
+	return &appUsage{windowStart: a.windowStart}
+}
+
+// user returns the logins into the app by the user, or nil if usage is not available.
+func (a *appUsageIndex) user(ctx context.Context, appId, userId string) *appUsage {
+	if a == nil {
+		return nil
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.refresh(ctx)
+	if a.windowStart.IsZero() {
+		return nil
+	}
+
+	if usage, ok := a.users[appId][userId]; ok {
+		return usage
+	}
+
+	return &appUsage{windowStart: a.windowStart}
+}
@@ -15,30 +15,48 @@ import (
 )
 
 type appResourceType struct {
-	resourceType *v2.ResourceType
-	client       *onelogin.Client
+	resourceType   *v2.ResourceType
+	client         *onelogin.Client
+	userAttributes *userAttributeIndex
+	assets         *assetCache
+	usage          *appUsageIndex
+	parameters     *appParametersCache
 }
 
 func (a *appResourceType) ResourceType(_ context.Context) *v2.ResourceType {
 	return a.resourceType
 }
 
-// Create a new connector resource for an OneLogin App.
-func appResource(app *onelogin.App) (*v2.Resource, error) {
+// Create a new connector resource for an OneLogin App, with its usage statistics if available.
+func appResource(app *onelogin.App, usage *appUsage, opts ...rs.ResourceOption) (*v2.Resource, error) {
 	profile := map[string]interface{}{
 		"app_id":   app.Id,
 		"app_name": app.Name,
 	}
+	if app.ConnectorId != 0 {
+		profile["connector_id"] = app.ConnectorId
+	}
+	if usage != nil {
+		for k, v := range usage.metadata(true) {
+			profile[k] = v
+		}
+	}
+
+	opts = append(opts, rs.WithAnnotation(&v2.ChildResourceType{ResourceTypeId: resourceTypeAppRule.Id}))
 
 	appTraitOptions := []rs.AppTraitOption{
 		rs.WithAppProfile(profile),
 	}
+	if app.IconURL != "" {
+		appTraitOptions = append(appTraitOptions, rs.WithAppIcon(&v2.AssetRef{Id: appIconAssetId(app.Id)}))
+	}
 
 	resource, err := rs.NewAppResource(
 		app.Name,
 		resourceTypeApp,
 		app.Id,
 		appTraitOptions,
+		opts...,
 	)
 
 	if err != nil {
@@ -48,19 +66,32 @@ func appResource(app *onelogin.App) (*v2.Resource, error) {
 	return resource, nil
 }
 
-func (a *appResourceType) List(ctx context.Context, _ *v2.ResourceId, pt *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+// List returns the apps built from the parent catalog connector. Apps without a catalog connector
+// are listed at the top level, so every app is synced exactly once.
+func (a *appResourceType) List(ctx context.Context, parentResourceID *v2.ResourceId, pt *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	if parentResourceID != nil && parentResourceID.ResourceType != resourceTypeCatalogConnector.Id {
+		return nil, "", nil, nil
+	}
+
 	bag, cursor, err := parsePageToken(pt.Token, &v2.ResourceId{ResourceType: resourceTypeApp.Id})
 	if err != nil {
 		return nil, "", nil, err
 	}
 
-	apps, nextCursor, err := a.client.GetApps(
-		ctx,
-		onelogin.PaginationVars{
-			Limit:  ResourcesPageSize,
-			Cursor: cursor,
-		},
-	)
+	paginationVars := onelogin.PaginationVars{
+		Limit:  ResourcesPageSize,
+		Cursor: cursor,
+	}
+
+	var apps []onelogin.App
+	var nextCursor string
+	var opts []rs.ResourceOption
+	if parentResourceID == nil {
+		apps, nextCursor, err = a.client.GetApps(ctx, paginationVars)
+	} else {
+		apps, nextCursor, err = a.client.GetConnectorApps(ctx, parentResourceID.Resource, paginationVars)
+		opts = append(opts, rs.WithParentResourceID(parentResourceID))
+	}
 	if err != nil {
 		return nil, "", nil, fmt.Errorf("onelogin-connector: failed to list apps: %w", err)
 	}
@@ -72,12 +103,19 @@ func (a *appResourceType) List(ctx context.Context, _ *v2.ResourceId, pt *pagina
 
 	var rv []*v2.Resource
 	for _, app := range apps {
+		if parentResourceID == nil && app.ConnectorId != 0 {
+			continue
+		}
+
 		appCopy := app
-		ur, err := appResource(&appCopy)
+		a.assets.rememberIcon(&appCopy)
+		usage := a.usage.app(ctx, strconv.Itoa(app.Id))
+		ur, err := appResource(&appCopy, usage, opts...)
 
 		if err != nil {
 			return nil, "", nil, err
 		}
+		addExternalLink(a.client, ur)
 
 		rv = append(rv, ur)
 	}
@@ -85,10 +123,10 @@ func (a *appResourceType) List(ctx context.Context, _ *v2.ResourceId, pt *pagina
 	return rv, nextPage, nil, nil
 }
 
-func (a *appResourceType) Entitlements(_ context.Context, resource *v2.Resource, token *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+func (a *appResourceType) Entitlements(ctx context.Context, resource *v2.Resource, token *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
 	var rv []*v2.Entitlement
 	memberAssignmentOptions := []ent.EntitlementOption{
-		ent.WithGrantableTo(resourceTypeUser),
+		ent.WithGrantableTo(resourceTypeUser, resourceTypeRole),
 		ent.WithDisplayName(fmt.Sprintf("%s App %s", resource.DisplayName, roleMembership)),
 		ent.WithDescription(fmt.Sprintf("Access to %s app in OneLogin", resource.DisplayName)),
 	}
@@ -102,6 +140,12 @@ func (a *appResourceType) Entitlements(_ context.Context, resource *v2.Resource,
 		),
 	)
 
+	parameterEntitlements, err := a.appParameterEntitlements(ctx, resource)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	rv = append(rv, parameterEntitlements...)
+
 	return rv, "", nil, nil
 }
 
@@ -111,37 +155,79 @@ func (a *appResourceType) Grants(ctx context.Context, resource *v2.Resource, tok
 		return nil, "", nil, err
 	}
 
-	appUsers, nextCursor, err := a.client.GetAppUsers(
-		ctx,
-		resource.Id.Resource,
-		onelogin.PaginationVars{
-			Limit:  ResourcesPageSize,
-			Cursor: cursor,
-		},
-	)
-	if err != nil {
-		return nil, "", nil, fmt.Errorf("onelogin-connector: failed to list app users: %w", err)
-	}
-
 	var rv []*v2.Grant
+	switch bag.ResourceTypeID() {
+	case resourceTypeApp.Id:
+		bag.Pop()
+		// get app users
+		bag.Push(pagination.PageState{
+			ResourceTypeID: resourceTypeUser.Id,
+		})
+		// get app parameter values
+		bag.Push(pagination.PageState{
+			ResourceTypeID: appParameterResourceId,
+		})
+
+	case resourceTypeUser.Id:
+		appUsers, nextCursor, err := a.client.GetAppUsers(
+			ctx,
+			resource.Id.Resource,
+			onelogin.PaginationVars{
+				Limit:  ResourcesPageSize,
+				Cursor: cursor,
+			},
+		)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("onelogin-connector: failed to list app users: %w", err)
+		}
 
-	for _, app := range appUsers {
-		userResource := &v2.ResourceId{
-			ResourceType: resourceTypeUser.Id,
-			Resource:     strconv.Itoa(app.Id),
+		for _, app := range appUsers {
+			userResource := &v2.ResourceId{
+				ResourceType: resourceTypeUser.Id,
+				Resource:     strconv.Itoa(app.Id),
+			}
+
+			var grantOptions []grant.GrantOption
+			if usage := a.usage.user(ctx, resource.Id.Resource, userResource.Resource); usage != nil {
+				grantOptions = append(grantOptions, grantMetadataOption(usage.metadata(false)))
+			}
+
+			rv = append(
+				rv,
+				grant.NewGrant(
+					resource,
+					roleMembership,
+					userResource,
+					grantOptions...,
+				),
+			)
 		}
 
-		rv = append(
-			rv,
-			grant.NewGrant(
-				resource,
-				roleMembership,
-				userResource,
-			),
-		)
+		nextPage, err := bag.NextToken(nextCursor)
+		if err != nil {
+			return nil, "", nil, err
+		}
+
+		return rv, nextPage, nil, nil
+
+	case appParameterResourceId:
+		rv, err = a.appParameterGrants(ctx, resource)
+		if err != nil {
+			return nil, "", nil, err
+		}
+
+		nextPage, err := bag.NextToken("")
+		if err != nil {
+			return nil, "", nil, err
+		}
+
+		return rv, nextPage, nil, nil
+
+	default:
+		return nil, "", nil, fmt.Errorf("unknown resource type: %s", bag.ResourceTypeID())
 	}
 
-	nextPage, err := bag.NextToken(nextCursor)
+	nextPage, err := bag.Marshal()
 	if err != nil {
 		return nil, "", nil, err
 	}
@@ -149,9 +235,13 @@ func (a *appResourceType) Grants(ctx context.Context, resource *v2.Resource, tok
 	return rv, nextPage, nil, nil
 }
 
-func appBuilder(client *onelogin.Client) *appResourceType {
+func appBuilder(client *onelogin.Client, userAttributes *userAttributeIndex, assets *assetCache, usage *appUsageIndex) *appResourceType {
 	return &appResourceType{
-		resourceType: resourceTypeApp,
-		client:       client,
+		resourceType:   resourceTypeApp,
+		client:         client,
+		userAttributes: userAttributes,
+		assets:         assets,
+		usage:          usage,
+		parameters:     newAppParametersCache(),
 	}
 }
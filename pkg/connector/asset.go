@@ -0,0 +1,141 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/conductorone/baton-onelogin/pkg/onelogin"
+)
+
+const appIconAssetPrefix = "app_icon:"
+
+// maxCachedAssets bounds the number of downloaded assets kept in memory; the oldest are evicted first.
+const maxCachedAssets = 256
+
+// asset is a downloaded asset along with its content type.
+type asset struct {
+	contentType string
+	data        []byte
+}
+
+func appIconAssetId(appId int) string {
+	return fmt.Sprintf("%s%d", appIconAssetPrefix, appId)
+}
+
+// assetDownload is a download in flight; waiters block on done and then read the result.
+type assetDownload struct {
+	done  chan struct{}
+	asset *asset
+	err   error
+}
+
+// assetCache resolves and caches the app icons referenced by app resources. Icon URLs seen while
+// listing apps are remembered, so serving an icon usually takes a single download. Concurrent
+// requests for the same asset share one download, which runs without holding the lock.
+type assetCache struct {
+	client   *onelogin.Client
+	mutex    sync.Mutex
+	iconURLs map[string]string
+	assets   map[string]*asset
+	order    []string
+	inFlight map[string]*assetDownload
+}
+
+func newAssetCache(client *onelogin.Client) *assetCache {
+	return &assetCache{
+		client:   client,
+		iconURLs: make(map[string]string),
+		assets:   make(map[string]*asset),
+		inFlight: make(map[string]*assetDownload),
+	}
+}
+
+// rememberIcon records the icon URL of an app listed during the sync.
+func (a *assetCache) rememberIcon(app *onelogin.App) {
+	if app.IconURL == "" {
+		return
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.iconURLs[appIconAssetId(app.Id)] = app.IconURL
+}
+
+// get returns the asset with the given ID, downloading it on first use.
+func (a *assetCache) get(ctx context.Context, assetId string) (*asset, error) {
+	if !strings.HasPrefix(assetId, appIconAssetPrefix) {
+		return nil, fmt.Errorf("onelogin-connector: unknown asset %s", assetId)
+	}
+
+	a.mutex.Lock()
+	if cached, ok := a.assets[assetId]; ok {
+		a.mutex.Unlock()
+		return cached, nil
+	}
+
+	if download, ok := a.inFlight[assetId]; ok {
+		a.mutex.Unlock()
+		select {
+		case <-download.done:
+			return download.asset, download.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	download := &assetDownload{done: make(chan struct{})}
+	a.inFlight[assetId] = download
+	iconURL := a.iconURLs[assetId]
+	a.mutex.Unlock()
+
+	download.asset, download.err = a.download(ctx, assetId, iconURL)
+
+	a.mutex.Lock()
+	delete(a.inFlight, assetId)
+	if download.err == nil {
+		a.put(assetId, download.asset)
+	}
+	a.mutex.Unlock()
+	close(download.done)
+
+	return download.asset, download.err
+}
+
+// download fetches an asset, looking up the icon URL of the app when it was not seen while listing.
+func (a *assetCache) download(ctx context.Context, assetId string, iconURL string) (*asset, error) {
+	if iconURL == "" {
+		appId := strings.TrimPrefix(assetId, appIconAssetPrefix)
+		app, err := a.client.GetApp(ctx, appId)
+		if err != nil {
+			return nil, fmt.Errorf("onelogin-connector: failed to get app %s: %w", appId, err)
+		}
+		if app.IconURL == "" {
+			return nil, fmt.Errorf("onelogin-connector: app %s has no icon", appId)
+		}
+		iconURL = app.IconURL
+	}
+
+	data, contentType, err := a.client.GetAsset(ctx, iconURL)
+	if err != nil {
+		return nil, fmt.Errorf("onelogin-connector: failed to download asset %s: %w", assetId, err)
+	}
+
+	return &asset{
+		contentType: contentType,
+		data:        data,
+	}, nil
+}
+
+// put caches an asset, evicting the oldest once the cache is full. It must be called with the lock held.
+func (a *assetCache) put(assetId string, downloaded *asset) {
+	if len(a.order) >= maxCachedAssets {
+		delete(a.assets, a.order[0])
+		a.order = a.order[1:]
+	}
+
+	a.assets[assetId] = downloaded
+	a.order = append(a.order, assetId)
+}
@@ -0,0 +1,152 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	ent "github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+	rs "github.com/conductorone/baton-sdk/pkg/types/resource"
+)
+
+// attributeGroupResourceType derives synthetic groups from a user attribute, one per distinct value.
+// They are not OneLogin objects, which their resource type, description and profile make explicit.
+type attributeGroupResourceType struct {
+	resourceType   *v2.ResourceType
+	attribute      string
+	userAttributes *userAttributeIndex
+}
+
+func (a *attributeGroupResourceType) ResourceType(_ context.Context) *v2.ResourceType {
+	return a.resourceType
+}
+
+// newAttributeGroupResourceType returns the resource type of the groups derived from the attribute.
+func newAttributeGroupResourceType(attribute string) *v2.ResourceType {
+	return &v2.ResourceType{
+		Id:          fmt.Sprintf("attribute_group_%s", attribute),
+		DisplayName: fmt.Sprintf("Derived %s Group", attribute),
+		Traits: []v2.ResourceType_Trait{
+			v2.ResourceType_TRAIT_GROUP,
+		},
+	}
+}
+
+// Create a new connector resource for the group of users holding the attribute value.
+func (a *attributeGroupResourceType) attributeGroupResource(value string, members int) (*v2.Resource, error) {
+	profile := map[string]interface{}{
+		"derived":          true,
+		"source_attribute": a.attribute,
+		"attribute_value":  value,
+		"member_count":     members,
+	}
+
+	groupTraitOptions := []rs.GroupTraitOption{
+		rs.WithGroupProfile(profile),
+	}
+
+	resource, err := rs.NewGroupResource(
+		fmt.Sprintf("%s: %s", a.attribute, value),
+		a.resourceType,
+		value,
+		groupTraitOptions,
+		rs.WithDescription(fmt.Sprintf("Derived from the %s user attribute, not a OneLogin group", a.attribute)),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return resource, nil
+}
+
+func (a *attributeGroupResourceType) List(ctx context.Context, _ *v2.ResourceId, _ *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	users, err := a.userAttributes.all(ctx)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	members := make(map[string]int)
+	for _, user := range users {
+		if value := user.Value(a.attribute); value != "" {
+			members[value]++
+		}
+	}
+
+	values := make([]string, 0, len(members))
+	for value := range members {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+
+	var rv []*v2.Resource
+	for _, value := range values {
+		gr, err := a.attributeGroupResource(value, members[value])
+		if err != nil {
+			return nil, "", nil, err
+		}
+
+		rv = append(rv, gr)
+	}
+
+	return rv, "", nil, nil
+}
+
+func (a *attributeGroupResourceType) Entitlements(_ context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	var rv []*v2.Entitlement
+	memberAssignmentOptions := []ent.EntitlementOption{
+		ent.WithGrantableTo(resourceTypeUser),
+		ent.WithDisplayName(fmt.Sprintf("%s %s", resource.DisplayName, roleMembership)),
+		ent.WithDescription(fmt.Sprintf("Users whose %s attribute is %s in OneLogin", a.attribute, resource.Id.Resource)),
+	}
+
+	rv = append(
+		rv,
+		ent.NewAssignmentEntitlement(
+			resource,
+			roleMembership,
+			memberAssignmentOptions...,
+		),
+	)
+
+	return rv, "", nil, nil
+}
+
+func (a *attributeGroupResourceType) Grants(ctx context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	userIds, err := a.userAttributes.matching(ctx, a.attribute, resource.Id.Resource)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	var rv []*v2.Grant
+	for _, userId := range userIds {
+		userResource := &v2.ResourceId{
+			ResourceType: resourceTypeUser.Id,
+			Resource:     strconv.Itoa(userId),
+		}
+
+		rv = append(
+			rv,
+			grant.NewGrant(
+				resource,
+				roleMembership,
+				userResource,
+			),
+		)
+	}
+
+	return rv, "", nil, nil
+}
+
+func attributeGroupBuilder(attribute string, userAttributes *userAttributeIndex) *attributeGroupResourceType {
+	return &attributeGroupResourceType{
+		resourceType:   newAttributeGroupResourceType(attribute),
+		attribute:      attribute,
+		userAttributes: userAttributes,
+	}
+}
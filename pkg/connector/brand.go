@@ -0,0 +1,164 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/conductorone/baton-onelogin/pkg/onelogin"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	ent "github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+)
+
+const brandAssigned = "assigned"
+
+type brandResourceType struct {
+	resourceType *v2.ResourceType
+	client       *onelogin.Client
+}
+
+func (b *brandResourceType) ResourceType(_ context.Context) *v2.ResourceType {
+	return b.resourceType
+}
+
+// Create a new connector resource for an OneLogin brand, along with the templates it customizes.
+func brandResource(brand *onelogin.Brand, templates []onelogin.BrandTemplate) (*v2.Resource, error) {
+	var brandTemplates []interface{}
+	for _, template := range templates {
+		brandTemplates = append(brandTemplates, map[string]interface{}{
+			"template_id": template.Id,
+			"type":        template.Type,
+			"locale":      template.Locale,
+		})
+	}
+
+	profile := map[string]interface{}{
+		"brand_id":               brand.Id,
+		"brand_name":             brand.Name,
+		"brand_enabled":          brand.Enabled,
+		"custom_domain":          brand.CustomDomain,
+		"custom_support_enabled": brand.CustomSupportEnabled,
+		"hide_onelogin_footer":   brand.HideOneLoginFooter,
+		"templates":              brandTemplates,
+	}
+
+	resource, err := inventoryResource(
+		brand.Name,
+		resourceTypeBrand,
+		brand.Id,
+		profile,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return resource, nil
+}
+
+func (b *brandResourceType) List(ctx context.Context, _ *v2.ResourceId, _ *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	brands, err := b.client.GetBrands(ctx)
+	if err != nil {
+		if skipUnavailable(ctx, err, "brands") {
+			return nil, "", nil, nil
+		}
+		return nil, "", nil, fmt.Errorf("onelogin-connector: failed to list brands: %w", err)
+	}
+
+	var rv []*v2.Resource
+	for _, brand := range brands {
+		brandCopy := brand
+
+		templates, err := b.client.GetBrandTemplates(ctx, strconv.Itoa(brand.Id))
+		if err != nil && !skipUnavailable(ctx, err, "brand templates") {
+			return nil, "", nil, fmt.Errorf("onelogin-connector: failed to list templates of brand %d: %w", brand.Id, err)
+		}
+
+		br, err := brandResource(&brandCopy, templates)
+
+		if err != nil {
+			return nil, "", nil, err
+		}
+		addExternalLink(b.client, br)
+
+		rv = append(rv, br)
+	}
+
+	return rv, "", nil, nil
+}
+
+func (b *brandResourceType) Entitlements(_ context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	var rv []*v2.Entitlement
+	assignmentOptions := []ent.EntitlementOption{
+		ent.WithGrantableTo(resourceTypeApp),
+		ent.WithDisplayName(fmt.Sprintf("%s Brand %s", resource.DisplayName, brandAssigned)),
+		ent.WithDescription(fmt.Sprintf("Apps presented with the %s brand in OneLogin", resource.DisplayName)),
+	}
+
+	rv = append(
+		rv,
+		ent.NewAssignmentEntitlement(
+			resource,
+			brandAssigned,
+			assignmentOptions...,
+		),
+	)
+
+	return rv, "", nil, nil
+}
+
+func (b *brandResourceType) Grants(ctx context.Context, resource *v2.Resource, pt *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	bag, cursor, err := parsePageToken(pt.Token, resource.Id)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	brandApps, nextCursor, err := b.client.GetBrandApps(
+		ctx,
+		resource.Id.Resource,
+		onelogin.PaginationVars{
+			Limit:  ResourcesPageSize,
+			Cursor: cursor,
+		},
+	)
+	if err != nil {
+		if skipUnavailable(ctx, err, "brand apps") {
+			return nil, "", nil, nil
+		}
+		return nil, "", nil, fmt.Errorf("onelogin-connector: failed to list apps of brand %s: %w", resource.Id.Resource, err)
+	}
+
+	var rv []*v2.Grant
+	for _, app := range brandApps {
+		appResource := &v2.ResourceId{
+			ResourceType: resourceTypeApp.Id,
+			Resource:     strconv.Itoa(app.Id),
+		}
+
+		rv = append(
+			rv,
+			grant.NewGrant(
+				resource,
+				brandAssigned,
+				appResource,
+			),
+		)
+	}
+
+	nextPage, err := bag.NextToken(nextCursor)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	return rv, nextPage, nil, nil
+}
+
+func brandBuilder(client *onelogin.Client) *brandResourceType {
+	return &brandResourceType{
+		resourceType: resourceTypeBrand,
+		client:       client,
+	}
+}
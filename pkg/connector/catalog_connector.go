@@ -0,0 +1,213 @@
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/conductorone/baton-onelogin/pkg/onelogin"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	rs "github.com/conductorone/baton-sdk/pkg/types/resource"
+)
+
+// Phases of the catalog connector walk.
+const (
+	catalogConnectorAppsPage    = "apps"
+	catalogConnectorCatalogPage = "catalog"
+	catalogConnectorMissingPage = "missing"
+)
+
+// catalogConnectorPage is the page token of the catalog connector walk. It carries the connectors in
+// use that were not emitted yet along with their number of apps, so a sync resumed from a checkpoint
+// continues the walk where it stopped.
+type catalogConnectorPage struct {
+	Phase  string      `json:"phase"`
+	Cursor string      `json:"cursor,omitempty"`
+	InUse  map[int]int `json:"in_use,omitempty"`
+}
+
+func parseCatalogConnectorPage(token string) (*catalogConnectorPage, error) {
+	page := &catalogConnectorPage{Phase: catalogConnectorAppsPage, InUse: make(map[int]int)}
+	if token == "" {
+		return page, nil
+	}
+
+	err := json.Unmarshal([]byte(token), page)
+	if err != nil {
+		return nil, fmt.Errorf("onelogin-connector: invalid catalog connector page token: %w", err)
+	}
+
+	if page.InUse == nil {
+		page.InUse = make(map[int]int)
+	}
+
+	return page, nil
+}
+
+func (p *catalogConnectorPage) marshal() (string, error) {
+	token, err := json.Marshal(p)
+	if err != nil {
+		return "", err
+	}
+
+	return string(token), nil
+}
+
+type catalogConnectorResourceType struct {
+	resourceType *v2.ResourceType
+	client       *onelogin.Client
+}
+
+func (c *catalogConnectorResourceType) ResourceType(_ context.Context) *v2.ResourceType {
+	return c.resourceType
+}
+
+// Create a new connector resource for an OneLogin app catalog connector, with its apps nested beneath it.
+func catalogConnectorResource(connector *onelogin.CatalogConnector, apps int) (*v2.Resource, error) {
+	profile := map[string]interface{}{
+		"connector_id":          connector.Id,
+		"connector_name":        connector.Name,
+		"auth_method":           connector.AuthMethod,
+		"allows_new_parameters": connector.AllowsNewParameters,
+		"app_count":             apps,
+	}
+
+	resource, err := inventoryResource(
+		connector.Name,
+		resourceTypeCatalogConnector,
+		connector.Id,
+		profile,
+		rs.WithAnnotation(&v2.ChildResourceType{ResourceTypeId: resourceTypeApp.Id}),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return resource, nil
+}
+
+// List returns the catalog connectors that apps are built from. The catalog holds thousands of
+// connectors, so only those in use are kept. The walk is paged: the apps are paged through first to
+// find the connectors in use, then the catalog to name them, and last the connectors in use that the
+// catalog no longer lists.
+func (c *catalogConnectorResourceType) List(ctx context.Context, _ *v2.ResourceId, pt *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	page, err := parseCatalogConnectorPage(pt.Token)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	var rv []*v2.Resource
+	switch page.Phase {
+	case catalogConnectorAppsPage:
+		apps, nextCursor, err := c.client.GetApps(ctx, onelogin.PaginationVars{
+			Limit:  ResourcesPageSize,
+			Cursor: page.Cursor,
+		})
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("onelogin-connector: failed to list apps: %w", err)
+		}
+
+		for _, app := range apps {
+			// apps without a connector are listed at the top level
+			if app.ConnectorId != 0 {
+				page.InUse[app.ConnectorId]++
+			}
+		}
+
+		page.Cursor = nextCursor
+		if nextCursor == "" {
+			if len(page.InUse) == 0 {
+				return nil, "", nil, nil
+			}
+			page.Phase = catalogConnectorCatalogPage
+		}
+
+	case catalogConnectorCatalogPage:
+		connectors, nextCursor, err := c.client.GetCatalogConnectors(ctx, onelogin.PaginationVars{
+			Limit:  ResourcesPageSize,
+			Cursor: page.Cursor,
+		})
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("onelogin-connector: failed to list catalog connectors: %w", err)
+		}
+
+		for _, connector := range connectors {
+			apps, ok := page.InUse[connector.Id]
+			if !ok {
+				continue
+			}
+			delete(page.InUse, connector.Id)
+
+			connectorCopy := connector
+			cr, err := catalogConnectorResource(&connectorCopy, apps)
+			if err != nil {
+				return nil, "", nil, err
+			}
+			addExternalLink(c.client, cr)
+
+			rv = append(rv, cr)
+		}
+
+		page.Cursor = nextCursor
+		if len(page.InUse) == 0 {
+			return rv, "", nil, nil
+		}
+		if nextCursor == "" {
+			page.Phase = catalogConnectorMissingPage
+		}
+
+	case catalogConnectorMissingPage:
+		var connectorIds []int
+		for id := range page.InUse {
+			connectorIds = append(connectorIds, id)
+		}
+		sort.Ints(connectorIds)
+
+		for _, id := range connectorIds {
+			// keep apps whose connector is missing from the catalog, e.g. a retired one, reachable
+			connector := onelogin.CatalogConnector{
+				BaseResource: onelogin.BaseResource{Id: id},
+				Name:         fmt.Sprintf("Connector %d", id),
+			}
+
+			cr, err := catalogConnectorResource(&connector, page.InUse[id])
+			if err != nil {
+				return nil, "", nil, err
+			}
+
+			rv = append(rv, cr)
+		}
+
+		return rv, "", nil, nil
+
+	default:
+		return nil, "", nil, fmt.Errorf("onelogin-connector: unknown catalog connector walk phase: %s", page.Phase)
+	}
+
+	nextPage, err := page.marshal()
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	return rv, nextPage, nil, nil
+}
+
+// Catalog connectors are templates for apps, so they have no entitlements or grants of their own.
+func (c *catalogConnectorResourceType) Entitlements(_ context.Context, _ *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	return nil, "", nil, nil
+}
+
+func (c *catalogConnectorResourceType) Grants(_ context.Context, _ *v2.Resource, _ *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	return nil, "", nil, nil
+}
+
+func catalogConnectorBuilder(client *onelogin.Client) *catalogConnectorResourceType {
+	return &catalogConnectorResourceType{
+		resourceType: resourceTypeCatalogConnector,
+		client:       client,
+	}
+}
@@ -0,0 +1,167 @@
+package connector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/conductorone/baton-onelogin/pkg/onelogin"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func resourceProfile(t *testing.T, resource *v2.Resource) map[string]interface{} {
+	t.Helper()
+
+	for _, annotation := range resource.Annotations {
+		profile := &structpb.Struct{}
+		if annotation.MessageIs(profile) {
+			err := annotation.UnmarshalTo(profile)
+			if err != nil {
+				t.Fatalf("failed to read profile: %v", err)
+			}
+			return profile.AsMap()
+		}
+	}
+
+	t.Fatalf("resource %s has no profile", resource.Id.Resource)
+	return nil
+}
+
+func catalogConnectorAPI(pageSize int) *fakeOneLogin {
+	app := func(id, connectorId int) onelogin.App {
+		return onelogin.App{BaseResource: onelogin.BaseResource{Id: id}, Name: "app", ConnectorId: connectorId}
+	}
+	connector := func(id int, name string) onelogin.CatalogConnector {
+		return onelogin.CatalogConnector{BaseResource: onelogin.BaseResource{Id: id}, Name: name}
+	}
+
+	return newFakeOneLogin(map[string]fakeRoute{
+		"GET /api/2/apps": pagedRoute([]onelogin.App{
+			app(1, 10), app(2, 0), app(3, 20), app(4, 10), app(5, 30),
+		}, pageSize),
+		"GET /api/2/connectors": pagedRoute([]onelogin.CatalogConnector{
+			connector(99, "Unused"), connector(20, "Salesforce"), connector(40, "Unused too"), connector(10, "Slack"),
+		}, pageSize),
+	})
+}
+
+// catalogConnectorWalk is the outcome of walking the catalog connectors, with the tokens of the pages
+// after the first one and how many connectors were emitted before each of them.
+type catalogConnectorWalk struct {
+	names   []string
+	counts  map[string]float64
+	tokens  []string
+	emitted []int
+}
+
+// walkCatalogConnectors lists catalog connectors from token until the walk ends, with a new builder
+// for every page like a sync resumed from a checkpoint.
+func walkCatalogConnectors(t *testing.T, api *fakeOneLogin, token string) *catalogConnectorWalk {
+	t.Helper()
+
+	client := api.client(t)
+	walk := &catalogConnectorWalk{counts: make(map[string]float64)}
+	for {
+		resources, next, _, err := catalogConnectorBuilder(client).List(context.Background(), nil, &pagination.Token{Token: token})
+		if err != nil {
+			t.Fatalf("failed to list catalog connectors: %v", err)
+		}
+
+		for _, resource := range resources {
+			walk.names = append(walk.names, resource.DisplayName)
+			walk.counts[resource.DisplayName] = resourceProfile(t, resource)["app_count"].(float64)
+		}
+
+		if next == "" {
+			return walk
+		}
+		walk.tokens = append(walk.tokens, next)
+		walk.emitted = append(walk.emitted, len(walk.names))
+		token = next
+	}
+}
+
+func TestCatalogConnectorList(t *testing.T) {
+	tests := []struct {
+		name     string
+		pageSize int
+	}{
+		{name: "single page", pageSize: 50},
+		{name: "several pages", pageSize: 2},
+		{name: "one item per page", pageSize: 1},
+	}
+
+	wantNames := []string{"Salesforce", "Slack", "Connector 30"}
+	wantCounts := map[string]float64{"Salesforce": 1, "Slack": 2, "Connector 30": 1}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			walk := walkCatalogConnectors(t, catalogConnectorAPI(tt.pageSize), "")
+			assertConnectors(t, walk.names, walk.counts, wantNames, wantCounts)
+
+			// resuming from any page of the walk yields the rest of the walk
+			for i, token := range walk.tokens {
+				resumed := walkCatalogConnectors(t, catalogConnectorAPI(tt.pageSize), token)
+
+				want := walk.names[walk.emitted[i]:]
+				if !equalStrings(resumed.names, want) {
+					t.Fatalf("resuming from page %d: expected %v, got %v", i+2, want, resumed.names)
+				}
+				for _, name := range resumed.names {
+					if resumed.counts[name] != wantCounts[name] {
+						t.Fatalf("resuming from page %d: connector %s has %v apps, expected %v", i+2, name, resumed.counts[name], wantCounts[name])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestCatalogConnectorListResumesMidWalk(t *testing.T) {
+	api := catalogConnectorAPI(2)
+	client := api.client(t)
+
+	// first page of apps, then the builder is gone
+	_, token, _, err := catalogConnectorBuilder(client).List(context.Background(), nil, &pagination.Token{})
+	if err != nil {
+		t.Fatalf("failed to list catalog connectors: %v", err)
+	}
+	if token == "" {
+		t.Fatalf("expected the walk to continue")
+	}
+
+	walk := walkCatalogConnectors(t, api, token)
+	assertConnectors(
+		t,
+		walk.names,
+		walk.counts,
+		[]string{"Salesforce", "Slack", "Connector 30"},
+		map[string]float64{"Salesforce": 1, "Slack": 2, "Connector 30": 1},
+	)
+}
+
+func assertConnectors(t *testing.T, names []string, counts map[string]float64, wantNames []string, wantCounts map[string]float64) {
+	t.Helper()
+
+	if !equalStrings(names, wantNames) {
+		t.Fatalf("expected connectors %v, got %v", wantNames, names)
+	}
+	for name, count := range wantCounts {
+		if counts[name] != count {
+			t.Fatalf("connector %s: expected %v apps, got %v", name, count, counts[name])
+		}
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
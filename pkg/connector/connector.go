@@ -3,7 +3,11 @@ package connector
 import (
 	"context"
 	"fmt"
+	"sync"
+	"time"
 
+	"github.com/conductorone/baton-onelogin/pkg/expiry"
+	"github.com/conductorone/baton-onelogin/pkg/journal"
 	"github.com/conductorone/baton-onelogin/pkg/onelogin"
 	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
 	"github.com/conductorone/baton-sdk/pkg/annotations"
@@ -42,28 +46,185 @@ var (
 			v2.ResourceType_TRAIT_GROUP,
 		},
 	}
+	resourceTypePrivilege = &v2.ResourceType{
+		Id:          "privilege",
+		DisplayName: "Privilege",
+		Traits: []v2.ResourceType_Trait{
+			v2.ResourceType_TRAIT_ROLE,
+		},
+	}
+	resourceTypeMapping = &v2.ResourceType{
+		Id:          "mapping",
+		DisplayName: "Mapping",
+		Annotations: annotationsForInventoryResourceType(),
+	}
+	resourceTypeAPIAuthorization = &v2.ResourceType{
+		Id:          "api_authorization",
+		DisplayName: "API Authorization",
+		Traits: []v2.ResourceType_Trait{
+			v2.ResourceType_TRAIT_APP,
+		},
+	}
+	resourceTypeSmartHook = &v2.ResourceType{
+		Id:          "smart_hook",
+		DisplayName: "Smart Hook",
+		Annotations: annotationsForInventoryResourceType(),
+	}
+	resourceTypeTrustedIdp = &v2.ResourceType{
+		Id:          "trusted_idp",
+		DisplayName: "Trusted IdP",
+	}
+	resourceTypeSelfRegistrationProfile = &v2.ResourceType{
+		Id:          "self_registration_profile",
+		DisplayName: "Self-Registration Profile",
+		Annotations: annotationsForInventoryResourceType(),
+	}
+	resourceTypeBrand = &v2.ResourceType{
+		Id:          "brand",
+		DisplayName: "Brand",
+	}
+	resourceTypePolicy = &v2.ResourceType{
+		Id:          "policy",
+		DisplayName: "Policy",
+	}
+	resourceTypeCatalogConnector = &v2.ResourceType{
+		Id:          "catalog_connector",
+		DisplayName: "Catalog Connector",
+		Annotations: annotationsForInventoryResourceType(),
+	}
+	resourceTypeAppRule = &v2.ResourceType{
+		Id:          "app_rule",
+		DisplayName: "App Rule",
+		Annotations: annotationsForInventoryResourceType(),
+	}
 )
 
 type OneLogin struct {
-	client *onelogin.Client
+	client         *onelogin.Client
+	provisioning   *provisioning
+	mappings       *mappingIndex
+	userAttributes *userAttributeIndex
+	policies       *policyAssignmentIndex
+	privileges     *rolePrivilegeIndex
+	assets         *assetCache
+	appUsage       *appUsageIndex
+	appLookback    time.Duration
+	attributes     []string
+	expiryInterval time.Duration
+	startExpiry    func()
+}
+
+// Option configures optional behaviour of the OneLogin connector.
+type Option func(*OneLogin)
+
+// WithProvisioningVerification makes Grant and Revoke wait until the change is visible in OneLogin,
+// failing once the timeout elapses. A zero timeout disables verification.
+func WithProvisioningVerification(timeout time.Duration) Option {
+	return func(o *OneLogin) {
+		o.provisioning.verifyTimeout = timeout
+	}
+}
+
+// WithProvisioningPolicy restricts Grant and Revoke according to the given policy.
+func WithProvisioningPolicy(policy ProvisioningPolicy) Option {
+	return func(o *OneLogin) {
+		o.provisioning.policy = policy
+	}
+}
+
+// WithProvisioningJournal records every grant, revoke and role change in the given journal.
+func WithProvisioningJournal(j *journal.Journal) Option {
+	return func(o *OneLogin) {
+		o.provisioning.journal = j
+	}
+}
+
+// WithProvisioningBatching coalesces role grants and revokes made within the window into array requests.
+func WithProvisioningBatching(window time.Duration) Option {
+	return func(o *OneLogin) {
+		o.provisioning.batchWindow = window
+	}
+}
+
+// WithGrantExpiry records role grants with an expiry in the given store, so they can be revoked once due.
+// defaultExpiry, a duration or RFC3339 timestamp, applies to grants whose request does not carry an expiry.
+func WithGrantExpiry(store *expiry.Store, defaultExpiry string) Option {
+	return func(o *OneLogin) {
+		o.provisioning.expiries = store
+		o.provisioning.defaultExpiry = defaultExpiry
+	}
+}
+
+// WithExpiryInterval revokes due grants every interval for as long as the connector runs.
+func WithExpiryInterval(interval time.Duration) Option {
+	return func(o *OneLogin) {
+		o.expiryInterval = interval
+	}
+}
+
+// WithAttributeGroups derives synthetic groups from each of the given user attributes, one per
+// distinct value. Custom attributes are named custom_attribute_<name>.
+func WithAttributeGroups(attributes ...string) Option {
+	return func(o *OneLogin) {
+		o.attributes = attributes
+	}
+}
+
+// WithAppUsage scans the app logins of the event log over the lookback window, to attach the last use
+// and use count of every app and app user. A zero lookback disables it.
+func WithAppUsage(lookback time.Duration) Option {
+	return func(o *OneLogin) {
+		o.appLookback = lookback
+	}
 }
 
 func (o *OneLogin) ResourceSyncers(ctx context.Context) []connectorbuilder.ResourceSyncer {
-	return []connectorbuilder.ResourceSyncer{
+	syncers := []connectorbuilder.ResourceSyncer{
 		userBuilder(o.client),
-		roleBuilder(o.client),
-		appBuilder(o.client),
-		groupBuilder(o.client),
+		roleBuilder(o.client, o.provisioning, o.mappings, o.privileges),
+		appBuilder(o.client, o.userAttributes, o.assets, o.appUsage),
+		groupBuilder(o.client, o.mappings, o.userAttributes),
+		privilegeBuilder(o.client, o.provisioning),
+		mappingBuilder(o.mappings),
+		appRuleBuilder(o.client),
+		apiAuthorizationBuilder(o.client, o.provisioning),
+		smartHookBuilder(o.client),
+		trustedIdpBuilder(o.client, o.userAttributes),
+		selfRegistrationProfileBuilder(o.client),
+		brandBuilder(o.client),
+		policyBuilder(o.client, o.policies),
+		catalogConnectorBuilder(o.client),
 	}
+
+	for _, attribute := range o.attributes {
+		syncers = append(syncers, attributeGroupBuilder(attribute, o.userAttributes))
+	}
+
+	return syncers
 }
 
 func (o *OneLogin) Metadata(ctx context.Context) (*v2.ConnectorMetadata, error) {
 	return &v2.ConnectorMetadata{
 		DisplayName: "OneLogin",
-		Description: "Connector syncing OneLogin users, roles, groups and applications to Baton.",
+		Description: "Connector syncing OneLogin users, roles, groups, applications, privileges, user mappings, app rules, API authorizations, Smart Hooks, trusted IdPs, self-registration profiles, brands, security policies and app catalog connectors to Baton.",
 	}, nil
 }
 
+// CreateRole creates a OneLogin role and returns it as a connector resource.
+func (o *OneLogin) CreateRole(ctx context.Context, body *onelogin.RoleBody) (*v2.Resource, error) {
+	return roleBuilder(o.client, o.provisioning, o.mappings, o.privileges).Create(ctx, body)
+}
+
+// RenameRole renames a OneLogin role and returns it as a connector resource.
+func (o *OneLogin) RenameRole(ctx context.Context, roleId, name string) (*v2.Resource, error) {
+	return roleBuilder(o.client, o.provisioning, o.mappings, o.privileges).Rename(ctx, roleId, name)
+}
+
+// DeleteRole deletes a OneLogin role, refusing roles with apps attached unless forced.
+func (o *OneLogin) DeleteRole(ctx context.Context, roleId string, force bool) error {
+	return roleBuilder(o.client, o.provisioning, o.mappings, o.privileges).Delete(ctx, roleId, force)
+}
+
 // Validates that credentials have required scope for the connector.
 func (o *OneLogin) Validate(ctx context.Context) (annotations.Annotations, error) {
 	_, err := o.client.ValidateScope(ctx, onelogin.PaginationVars{Limit: 1})
@@ -71,11 +232,15 @@ func (o *OneLogin) Validate(ctx context.Context) (annotations.Annotations, error
 		return nil, fmt.Errorf("onelogin-connector: unauthorized: %w", err)
 	}
 
+	if o.startExpiry != nil {
+		o.startExpiry()
+	}
+
 	return nil, nil
 }
 
 // New returns the OneLogin connector.
-func New(ctx context.Context, clientId, clientSecret, subdomain string) (*OneLogin, error) {
+func New(ctx context.Context, clientId, clientSecret, subdomain string, opts ...Option) (*OneLogin, error) {
 	httpClient, err := uhttp.NewClient(ctx, uhttp.WithLogger(true, ctxzap.Extract(ctx)))
 	if err != nil {
 		return nil, err
@@ -86,7 +251,35 @@ func New(ctx context.Context, clientId, clientSecret, subdomain string) (*OneLog
 		return nil, err
 	}
 
-	return &OneLogin{
-		client: oneLoginClient,
-	}, nil
+	userAttributes := newUserAttributeIndex(oneLoginClient)
+	oneLogin := &OneLogin{
+		client:         oneLoginClient,
+		provisioning:   &provisioning{},
+		mappings:       newMappingIndex(oneLoginClient),
+		userAttributes: userAttributes,
+		policies:       newPolicyAssignmentIndex(oneLoginClient, userAttributes),
+		privileges:     newRolePrivilegeIndex(oneLoginClient),
+		assets:         newAssetCache(oneLoginClient),
+	}
+	for _, opt := range opts {
+		opt(oneLogin)
+	}
+	oneLogin.appUsage = newAppUsageIndex(oneLoginClient, oneLogin.appLookback)
+
+	if oneLogin.provisioning.batchWindow > 0 {
+		oneLogin.provisioning.batcher = onelogin.NewRoleBatcher(ctx, oneLoginClient, oneLogin.provisioning.batchWindow)
+	}
+
+	// The SDK builds the connector in both the runner and the process serving it, so the expiry
+	// loop is only started once the connector is validated, which happens in the serving process.
+	if oneLogin.expiryInterval > 0 && oneLogin.provisioning.expiries != nil {
+		var once sync.Once
+		oneLogin.startExpiry = func() {
+			once.Do(func() {
+				go oneLogin.runExpiryLoop(ctx, oneLogin.expiryInterval)
+			})
+		}
+	}
+
+	return oneLogin, nil
 }
@@ -0,0 +1,219 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/conductorone/baton-onelogin/pkg/expiry"
+	"github.com/conductorone/baton-onelogin/pkg/journal"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	ent "github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const (
+	// grantExpiry is read from the metadata annotation of a Grant request, holding a duration or an RFC3339 timestamp.
+	grantExpiry = "grant_expiry"
+
+	// Keys of the metadata describing when a grant expires.
+	expiresAt = "expires_at"
+	expiresIn = "expires_in"
+
+	// expiryNotApplied flags a grant request whose expiry was ignored, because the membership was already in place.
+	expiryNotApplied = "expiry_not_applied"
+
+	expireAttempts = 3
+	expireBackoff  = 2 * time.Second
+)
+
+// ExpiryReport lists the expired grants that were revoked, those that would have been revoked in
+// dry-run mode, and those that failed and will be retried.
+type ExpiryReport struct {
+	Revoked []*expiry.Grant
+	DryRun  []*expiry.Grant
+	Failed  []*expiry.Grant
+}
+
+// requestedExpiry returns when a grant being made should expire, or a zero time if it should not.
+func (p *provisioning) requestedExpiry(ctx context.Context) (time.Time, error) {
+	value := p.defaultExpiry
+
+	metadata := &structpb.Struct{}
+	annos := requestAnnotations(ctx)
+	ok, err := annos.Pick(metadata)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if ok {
+		if requested := metadata.GetFields()[grantExpiry].GetStringValue(); requested != "" {
+			value = requested
+		}
+	}
+
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if p.expiries == nil {
+		return time.Time{}, fmt.Errorf("grant expiry requires an expiring grants store")
+	}
+
+	return expiry.ParseExpiry(value, time.Now())
+}
+
+// roleExpiries returns the expiry of the grants of a role, keyed by entitlement slug and user ID.
+func (p *provisioning) roleExpiries(ctx context.Context, roleId string) (map[string]time.Time, error) {
+	if p.expiries == nil {
+		return nil, nil
+	}
+
+	grants, err := p.expiries.ForRole(ctx, roleId)
+	if err != nil {
+		return nil, err
+	}
+
+	rv := make(map[string]time.Time, len(grants))
+	for _, g := range grants {
+		rv[expiryKey(g.Entitlement, g.UserId)] = g.ExpiresAt
+	}
+
+	return rv, nil
+}
+
+func expiryKey(entitlement, userId string) string {
+	return fmt.Sprintf("%s:%s", entitlement, userId)
+}
+
+func expiryMetadata(expiration time.Time) map[string]interface{} {
+	return map[string]interface{}{
+		expiresAt: expiration.UTC().Format(time.RFC3339),
+		expiresIn: time.Until(expiration).Round(time.Second).String(),
+	}
+}
+
+// ExpireGrants revokes every grant whose expiry has passed. Revokes are retried a few times,
+// grants that still fail stay in the store to be retried by the next run.
+func (o *OneLogin) ExpireGrants(ctx context.Context) (*ExpiryReport, error) {
+	l := ctxzap.Extract(ctx)
+
+	if o.provisioning.expiries == nil {
+		return nil, fmt.Errorf("onelogin-connector: expiring grants store is not configured")
+	}
+
+	due, err := o.provisioning.expiries.Due(ctx, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("onelogin-connector: failed to load expired grants: %w", err)
+	}
+
+	r := roleBuilder(o.client, o.provisioning, o.mappings, o.privileges)
+	report := &ExpiryReport{}
+	for _, g := range due {
+		dryRun, revokeErr := r.revokeExpired(ctx, g)
+		if revokeErr == nil {
+			// a dry run revokes nothing, so the grant stays due
+			if dryRun {
+				report.DryRun = append(report.DryRun, g)
+			} else {
+				report.Revoked = append(report.Revoked, g)
+			}
+			continue
+		}
+
+		l.Error(
+			"onelogin-connector: failed to revoke expired grant",
+			zap.String("role_id", g.RoleId),
+			zap.String("user_id", g.UserId),
+			zap.String("entitlement", g.Entitlement),
+			zap.Error(revokeErr),
+		)
+
+		err = o.provisioning.expiries.MarkFailed(ctx, g, revokeErr)
+		if err != nil {
+			return nil, fmt.Errorf("onelogin-connector: failed to record expired grant failure: %w", err)
+		}
+		g.Attempts++
+		g.LastError = revokeErr.Error()
+		report.Failed = append(report.Failed, g)
+	}
+
+	return report, nil
+}
+
+// runExpiryLoop expires grants every interval until the context is done.
+func (o *OneLogin) runExpiryLoop(ctx context.Context, interval time.Duration) {
+	l := ctxzap.Extract(ctx)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			report, err := o.ExpireGrants(ctx)
+			if err != nil {
+				l.Error("onelogin-connector: failed to expire grants", zap.Error(err))
+				continue
+			}
+
+			if len(report.Revoked) != 0 || len(report.DryRun) != 0 || len(report.Failed) != 0 {
+				l.Info(
+					"onelogin-connector: expired grants",
+					zap.Int("revoked", len(report.Revoked)),
+					zap.Int("dry_run", len(report.DryRun)),
+					zap.Int("failed", len(report.Failed)),
+				)
+			}
+		}
+	}
+}
+
+// revokeExpired revokes an expired grant through Revoke, retrying failures with a backoff. It reports
+// whether the revoke was only a dry run.
+func (r *roleResourceType) revokeExpired(ctx context.Context, g *expiry.Grant) (bool, error) {
+	role := &v2.Resource{
+		Id:          roleResourceId(g.RoleId),
+		DisplayName: g.RoleName,
+	}
+	expired := &v2.Grant{
+		Entitlement: &v2.Entitlement{
+			Id:       ent.NewEntitlementID(role, g.Entitlement),
+			Resource: role,
+			Slug:     g.Entitlement,
+		},
+		Principal: &v2.Resource{
+			Id: &v2.ResourceId{
+				ResourceType: resourceTypeUser.Id,
+				Resource:     g.UserId,
+			},
+		},
+	}
+
+	var err error
+	backoff := expireBackoff
+	for attempt := 1; attempt <= expireAttempts; attempt++ {
+		var annos annotations.Annotations
+		annos, err = r.Revoke(ctx, expired)
+		if err == nil {
+			return journalOutcome(annos, nil) == journal.OutcomeDryRun, nil
+		}
+
+		if attempt == expireAttempts {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+
+	return false, err
+}
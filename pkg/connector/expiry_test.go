@@ -0,0 +1,226 @@
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/conductorone/baton-onelogin/pkg/expiry"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	ent "github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// roleMembersAPI fakes the members of role 10, so role grants and revokes change what is read back.
+func roleMembersAPI(members ...string) *fakeOneLogin {
+	var mutex sync.Mutex
+	current := make(map[string]bool)
+	for _, member := range members {
+		current[member] = true
+	}
+
+	update := func(value bool) fakeRoute {
+		return func(req *http.Request) fakeResponse {
+			var userIds []string
+			err := json.NewDecoder(req.Body).Decode(&userIds)
+			if err != nil {
+				return fakeResponse{status: http.StatusBadRequest}
+			}
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			for _, userId := range userIds {
+				current[userId] = value
+			}
+			return fakeResponse{body: []interface{}{}}
+		}
+	}
+
+	userRoles := func(userId string) fakeRoute {
+		return func(_ *http.Request) fakeResponse {
+			mutex.Lock()
+			defer mutex.Unlock()
+			if current[userId] {
+				return fakeResponse{body: []int{10}}
+			}
+			return fakeResponse{body: []int{}}
+		}
+	}
+
+	return newFakeOneLogin(map[string]fakeRoute{
+		"POST /api/2/roles/10/users":   update(true),
+		"DELETE /api/2/roles/10/users": update(false),
+		"GET /api/2/users/1/roles":     userRoles("1"),
+		"GET /api/2/users/2/roles":     userRoles("2"),
+	})
+}
+
+func openExpiryStore(t *testing.T) *expiry.Store {
+	t.Helper()
+
+	store, err := expiry.Open(context.Background(), filepath.Join(t.TempDir(), "expiry.db"))
+	if err != nil {
+		t.Fatalf("failed to open expiry store: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	return store
+}
+
+func roleMemberEntitlement() *v2.Entitlement {
+	role := &v2.Resource{Id: roleResourceId("10"), DisplayName: "Support"}
+	return &v2.Entitlement{
+		Id:       ent.NewEntitlementID(role, roleMembership),
+		Resource: role,
+		Slug:     roleMembership,
+	}
+}
+
+func userPrincipal(userId string) *v2.Resource {
+	return &v2.Resource{Id: &v2.ResourceId{ResourceType: resourceTypeUser.Id, Resource: userId}}
+}
+
+func annotationMetadata(t *testing.T, annos annotations.Annotations) map[string]interface{} {
+	t.Helper()
+
+	metadata := &structpb.Struct{}
+	ok, err := annos.Pick(metadata)
+	if err != nil {
+		t.Fatalf("failed to read metadata: %v", err)
+	}
+	if !ok {
+		return nil
+	}
+
+	return metadata.AsMap()
+}
+
+func TestRoleGrantRecordsExpiryOnlyForNewMemberships(t *testing.T) {
+	tests := []struct {
+		name          string
+		members       []string
+		wantExpiry    bool
+		wantMetadata  string
+		wantGrantCall int
+	}{
+		{
+			name:          "new membership expires",
+			wantExpiry:    true,
+			wantMetadata:  expiresAt,
+			wantGrantCall: 1,
+		},
+		{
+			name:         "existing membership is left alone",
+			members:      []string{"1"},
+			wantMetadata: expiryNotApplied,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := roleMembersAPI(tt.members...)
+			store := openExpiryStore(t)
+			r := roleBuilder(api.client(t), &provisioning{expiries: store, defaultExpiry: "1h"}, nil, nil)
+
+			annos, err := r.Grant(context.Background(), userPrincipal("1"), roleMemberEntitlement())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if _, ok := annotationMetadata(t, annos)[tt.wantMetadata]; !ok {
+				t.Fatalf("expected %s in the grant metadata, got %v", tt.wantMetadata, annotationMetadata(t, annos))
+			}
+			if calls := api.called("POST /api/2/roles/10/users"); calls != tt.wantGrantCall {
+				t.Fatalf("expected %d grant requests, got %d", tt.wantGrantCall, calls)
+			}
+
+			grants, err := store.ForRole(context.Background(), "10")
+			if err != nil {
+				t.Fatalf("failed to list expiring grants: %v", err)
+			}
+			if (len(grants) == 1) != tt.wantExpiry || len(grants) > 1 {
+				t.Fatalf("expected expiry recorded: %v, got %d expiring grants", tt.wantExpiry, len(grants))
+			}
+		})
+	}
+}
+
+func TestExpireGrantsReportsDryRuns(t *testing.T) {
+	tests := []struct {
+		name        string
+		dryRun      bool
+		wantRevoked int
+		wantDryRun  int
+		wantDue     int
+		wantRevokes int
+	}{
+		{name: "revokes expired grants", wantRevoked: 2, wantRevokes: 1},
+		{name: "dry run keeps expired grants due", dryRun: true, wantDryRun: 1, wantRevoked: 1, wantDue: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			// user 1 still holds the role, user 2 already lost it
+			api := roleMembersAPI("1")
+			store := openExpiryStore(t)
+			for _, userId := range []string{"1", "2"} {
+				err := store.Put(ctx, &expiry.Grant{
+					RoleId:      "10",
+					UserId:      userId,
+					Entitlement: roleMembership,
+					ExpiresAt:   time.Now().Add(-time.Minute),
+				})
+				if err != nil {
+					t.Fatalf("failed to put grant: %v", err)
+				}
+			}
+
+			o := &OneLogin{
+				client: api.client(t),
+				provisioning: &provisioning{
+					expiries: store,
+					policy:   ProvisioningPolicy{DryRun: tt.dryRun},
+				},
+			}
+
+			report, err := o.ExpireGrants(ctx)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(report.Revoked) != tt.wantRevoked || len(report.DryRun) != tt.wantDryRun || len(report.Failed) != 0 {
+				t.Fatalf(
+					"expected %d revoked and %d dry run, got %d revoked, %d dry run and %d failed",
+					tt.wantRevoked, tt.wantDryRun, len(report.Revoked), len(report.DryRun), len(report.Failed),
+				)
+			}
+			for _, g := range report.DryRun {
+				if g.UserId != "1" {
+					t.Fatalf("expected only user 1 to be reported as dry run, got %s", g.UserId)
+				}
+			}
+
+			if calls := api.called("DELETE /api/2/roles/10/users"); calls != tt.wantRevokes {
+				t.Fatalf("expected %d revoke requests, got %d", tt.wantRevokes, calls)
+			}
+
+			due, err := store.Due(ctx, time.Now())
+			if err != nil {
+				t.Fatalf("failed to list due grants: %v", err)
+			}
+			if len(due) != tt.wantDue {
+				t.Fatalf("expected %d grants still due, got %d", tt.wantDue, len(due))
+			}
+			if tt.wantDue == 1 && due[0].UserId != "1" {
+				t.Fatalf("expected the grant of user 1 to stay due, got user %s", due[0].UserId)
+			}
+		})
+	}
+}
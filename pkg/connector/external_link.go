@@ -0,0 +1,46 @@
+package connector
+
+import (
+	"github.com/conductorone/baton-onelogin/pkg/onelogin"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+)
+
+// Admin console pages of the synced resource types, relative to the tenant URL. Smart Hooks are
+// only managed through the API and attribute groups are derived, so neither has a page.
+var adminConsolePaths = map[string]string{
+	resourceTypeUser.Id:                    "users/%s/edit",
+	resourceTypeRole.Id:                    "roles/%s",
+	resourceTypeApp.Id:                     "apps/%s/edit",
+	resourceTypeGroup.Id:                   "groups/%s/edit",
+	resourceTypePrivilege.Id:               "privileges/%s",
+	resourceTypeMapping.Id:                 "mappings/%s/edit",
+	resourceTypeAppRule.Id:                 "apps/%s/rules",
+	resourceTypeAPIAuthorization.Id:        "api_authorizations/%s",
+	resourceTypeTrustedIdp.Id:              "trusted_idps/%s/edit",
+	resourceTypeSelfRegistrationProfile.Id: "self_registration_profiles/%s/edit",
+	resourceTypeBrand.Id:                   "branding/brands/%s",
+	resourceTypePolicy.Id:                  "policies/%s/edit",
+	resourceTypeCatalogConnector.Id:        "apps/new/%s",
+}
+
+// addExternalLink annotates the resource with a link to its page in the OneLogin admin console.
+// App rules have no page of their own and link to the rules of their app.
+func addExternalLink(client *onelogin.Client, resource *v2.Resource) {
+	path, ok := adminConsolePaths[resource.Id.ResourceType]
+	if !ok {
+		return
+	}
+
+	id := resource.Id.Resource
+	if resource.Id.ResourceType == resourceTypeAppRule.Id {
+		if resource.ParentResourceId == nil {
+			return
+		}
+		id = resource.ParentResourceId.Resource
+	}
+
+	annos := annotations.Annotations(resource.Annotations)
+	annos.Update(&v2.ExternalLink{Url: client.AdminURL(path, id)})
+	resource.Annotations = annos
+}
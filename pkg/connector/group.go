@@ -15,8 +15,10 @@ import (
 )
 
 type groupResourceType struct {
-	resourceType *v2.ResourceType
-	client       *onelogin.Client
+	resourceType   *v2.ResourceType
+	client         *onelogin.Client
+	mappings       *mappingIndex
+	userAttributes *userAttributeIndex
 }
 
 func (g *groupResourceType) ResourceType(_ context.Context) *v2.ResourceType {
@@ -78,6 +80,7 @@ func (g *groupResourceType) List(ctx context.Context, _ *v2.ResourceId, pt *pagi
 		if err != nil {
 			return nil, "", nil, err
 		}
+		addExternalLink(g.client, ur)
 
 		rv = append(rv, ur)
 	}
@@ -123,6 +126,11 @@ func (g *groupResourceType) Grants(ctx context.Context, resource *v2.Resource, t
 		return nil, "", nil, fmt.Errorf("onelogin-connector: failed to list group users: %w", err)
 	}
 
+	mappings, err := g.mappings.groupMappings(ctx, resource.Id.Resource)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
 	var rv []*v2.Grant
 
 	for _, user := range users {
@@ -131,12 +139,22 @@ func (g *groupResourceType) Grants(ctx context.Context, resource *v2.Resource, t
 			Resource:     strconv.Itoa(user.Id),
 		}
 
+		// user attributes are only needed, and loaded, when a mapping sets the group
+		var attributes onelogin.UserAttributes
+		if len(mappings) > 0 {
+			attributes, err = g.userAttributes.get(ctx, user.Id)
+			if err != nil {
+				return nil, "", nil, err
+			}
+		}
+
 		rv = append(
 			rv,
 			grant.NewGrant(
 				resource,
 				roleMembership,
 				userResource,
+				grantMetadataOption(groupGrantSource(attributes, mappings)),
 			),
 		)
 	}
@@ -149,9 +167,11 @@ func (g *groupResourceType) Grants(ctx context.Context, resource *v2.Resource, t
 	return rv, nextPage, nil, nil
 }
 
-func groupBuilder(client *onelogin.Client) *groupResourceType {
+func groupBuilder(client *onelogin.Client, mappings *mappingIndex, userAttributes *userAttributeIndex) *groupResourceType {
 	return &groupResourceType{
-		resourceType: resourceTypeGroup,
-		client:       client,
+		resourceType:   resourceTypeGroup,
+		client:         client,
+		mappings:       mappings,
+		userAttributes: userAttributes,
 	}
 }
@@ -0,0 +1,91 @@
+package connector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/conductorone/baton-onelogin/pkg/onelogin"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+)
+
+func TestGroupGrantsAttributeMappings(t *testing.T) {
+	setGroup := func(id int, enabled bool, groupId string, conditions ...onelogin.RuleCondition) onelogin.Mapping {
+		return onelogin.Mapping{
+			BaseResource: onelogin.BaseResource{Id: id},
+			Enabled:      enabled,
+			Conditions:   conditions,
+			Actions:      []onelogin.RuleAction{{Action: mappingActionSetGroup, Value: []string{groupId}}},
+		}
+	}
+	engineering := onelogin.RuleCondition{Source: "department", Operator: ruleOperatorEquals, Value: "Engineering"}
+
+	tests := []struct {
+		name           string
+		mappings       []onelogin.Mapping
+		wantSources    map[string]string
+		wantMappingIds map[string]string
+		wantUserLists  int
+	}{
+		{
+			name: "matching mapping sets the group",
+			mappings: []onelogin.Mapping{
+				setGroup(5, true, "7", engineering),
+				setGroup(6, false, "7"),
+				setGroup(8, true, "9"),
+			},
+			wantSources:    map[string]string{"1": grantSourceMapping, "2": grantSourceManual},
+			wantMappingIds: map[string]string{"1": "5"},
+			wantUserLists:  2,
+		},
+		{
+			name: "no mapping sets the group",
+			mappings: []onelogin.Mapping{
+				setGroup(6, false, "7"),
+				setGroup(8, true, "9"),
+			},
+			wantSources:   map[string]string{"1": grantSourceManual, "2": grantSourceManual},
+			wantUserLists: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakeOneLogin(map[string]fakeRoute{
+				"GET /api/2/mappings": staticRoute(tt.mappings),
+				"GET /api/2/users": staticRoute([]map[string]interface{}{
+					{"id": 1, "group_id": 7, "department": "Engineering"},
+					{"id": 2, "group_id": 7, "department": "Sales"},
+				}),
+			})
+			client := api.client(t)
+			g := groupBuilder(client, newMappingIndex(client), newUserAttributeIndex(client))
+			group := &v2.Resource{Id: &v2.ResourceId{ResourceType: resourceTypeGroup.Id, Resource: "7"}}
+
+			grants, _, _, err := g.Grants(context.Background(), group, &pagination.Token{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(grants) != len(tt.wantSources) {
+				t.Fatalf("expected %d grants, got %d", len(tt.wantSources), len(grants))
+			}
+			for _, gr := range grants {
+				userId := gr.Principal.Id.Resource
+				metadata := annotationMetadata(t, annotations.Annotations(gr.Annotations))
+				if metadata[grantSource] != tt.wantSources[userId] {
+					t.Fatalf("user %s: expected source %s, got %v", userId, tt.wantSources[userId], metadata)
+				}
+				if mappingId, ok := tt.wantMappingIds[userId]; ok && metadata[grantMappingId] != mappingId {
+					t.Fatalf("user %s: expected mapping %s, got %v", userId, mappingId, metadata)
+				}
+			}
+
+			// users are only read again for their attributes when a mapping sets the group
+			if calls := api.called("GET /api/2/users"); calls != tt.wantUserLists {
+				t.Fatalf("expected %d user lists, got %d", tt.wantUserLists, calls)
+			}
+		})
+	}
+}
@@ -1,19 +1,102 @@
 package connector
 
 import (
+	"context"
+
+	"github.com/conductorone/baton-onelogin/pkg/onelogin"
 	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
 	"github.com/conductorone/baton-sdk/pkg/annotations"
 	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+	rs "github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/structpb"
 )
 
 var ResourcesPageSize = 50
 
+// Keys of the metadata returned from provisioning when nothing needed to change, which the
+// provisioning journal records as the outcome. They are the connector's own metadata: the SDK
+// version in use has no annotation for such outcomes and does not act on them.
+const (
+	grantAlreadyExists  = "grant_already_exists"
+	grantAlreadyRevoked = "grant_already_revoked"
+)
+
 func annotationsForUserResourceType() annotations.Annotations {
 	annos := annotations.Annotations{}
 	annos.Update(&v2.SkipEntitlementsAndGrants{})
 	return annos
 }
 
+// annotationsForInventoryResourceType is used by resources synced for review only, without entitlements or grants.
+func annotationsForInventoryResourceType() annotations.Annotations {
+	annos := annotations.Annotations{}
+	annos.Update(&v2.SkipEntitlementsAndGrants{})
+	return annos
+}
+
+// inventoryResource creates a resource without traits for objects synced for review, which are neither
+// roles nor apps. Its profile is carried as a resource annotation.
+func inventoryResource(name string, resourceType *v2.ResourceType, objectID interface{}, profile map[string]interface{}, opts ...rs.ResourceOption) (*v2.Resource, error) {
+	md, err := structpb.NewStruct(profile)
+	if err != nil {
+		return nil, err
+	}
+
+	return rs.NewResource(name, resourceType, objectID, append(opts, rs.WithAnnotation(md))...)
+}
+
+// skipUnavailable reports whether data the API credentials may not read, or the tenant does not have,
+// should be left out of the sync rather than fail it. Skipped data is logged.
+func skipUnavailable(ctx context.Context, err error, what string) bool {
+	if !onelogin.IsUnavailable(err) {
+		return false
+	}
+
+	ctxzap.Extract(ctx).Warn(
+		"onelogin-connector: skipping data the API credentials cannot read",
+		zap.String("data", what),
+		zap.Error(err),
+	)
+	return true
+}
+
+// provisioningAnnotations wraps metadata describing the outcome of a Grant or Revoke into annotations.
+func provisioningAnnotations(metadata map[string]interface{}) (annotations.Annotations, error) {
+	md, err := structpb.NewStruct(metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	annos := annotations.Annotations{}
+	annos.Update(md)
+	return annos, nil
+}
+
+// grantMetadataOption merges the given metadata into a single grant metadata option,
+// as the SDK keeps only the last metadata set on a grant.
+func grantMetadataOption(metadata ...map[string]interface{}) grant.GrantOption {
+	merged := make(map[string]interface{})
+	for _, md := range metadata {
+		for key, value := range md {
+			merged[key] = value
+		}
+	}
+
+	return grant.WithGrantMetadata(merged)
+}
+
+func stringsToInterfaces(values []string) []interface{} {
+	rv := make([]interface{}, 0, len(values))
+	for _, value := range values {
+		rv = append(rv, value)
+	}
+
+	return rv
+}
+
 func parsePageToken(i string, resourceID *v2.ResourceId) (*pagination.Bag, string, error) {
 	b := &pagination.Bag{}
 	err := b.Unmarshal(i)
@@ -0,0 +1,139 @@
+package connector
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/conductorone/baton-onelogin/pkg/onelogin"
+)
+
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// fakeResponse is the answer of a fake OneLogin API route.
+type fakeResponse struct {
+	status int
+	body   interface{}
+	cursor string
+}
+
+type fakeRoute func(req *http.Request) fakeResponse
+
+// fakeOneLogin serves OneLogin API requests from routes keyed by method and path, e.g. "GET /api/2/apps".
+type fakeOneLogin struct {
+	mutex    sync.Mutex
+	routes   map[string]fakeRoute
+	requests []string
+}
+
+func newFakeOneLogin(routes map[string]fakeRoute) *fakeOneLogin {
+	return &fakeOneLogin{routes: routes}
+}
+
+// client returns a client talking to the fake API.
+func (f *fakeOneLogin) client(t *testing.T) *onelogin.Client {
+	t.Helper()
+
+	httpClient := &http.Client{Transport: roundTripFunc(f.roundTrip)}
+	client, err := onelogin.NewClient(context.Background(), httpClient, "id", "secret", "example")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	return client
+}
+
+// called returns the number of requests made to the route.
+func (f *fakeOneLogin) called(route string) int {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	count := 0
+	for _, request := range f.requests {
+		if request == route {
+			count++
+		}
+	}
+
+	return count
+}
+
+func (f *fakeOneLogin) roundTrip(req *http.Request) (*http.Response, error) {
+	key := req.Method + " " + req.URL.Path
+
+	var response fakeResponse
+	if key == "POST /auth/oauth2/v2/token" {
+		response = fakeResponse{body: map[string]string{"access_token": "token"}}
+	} else {
+		f.mutex.Lock()
+		f.requests = append(f.requests, key)
+		route, ok := f.routes[key]
+		f.mutex.Unlock()
+
+		if ok {
+			response = route(req)
+		} else {
+			response = fakeResponse{status: http.StatusNotFound}
+		}
+	}
+
+	if response.status == 0 {
+		response.status = http.StatusOK
+	}
+
+	body, err := json.Marshal(response.body)
+	if err != nil {
+		return nil, err
+	}
+
+	header := http.Header{}
+	if response.cursor != "" {
+		header.Set("after-cursor", response.cursor)
+	}
+
+	return &http.Response{
+		StatusCode: response.status,
+		Header:     header,
+		Body:       io.NopCloser(bytes.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+// pagedRoute serves items pageSize at a time, using the offset of the next page as cursor.
+func pagedRoute[T any](items []T, pageSize int) fakeRoute {
+	return func(req *http.Request) fakeResponse {
+		offset, _ := strconv.Atoi(req.URL.Query().Get("cursor"))
+
+		end := offset + pageSize
+		cursor := strconv.Itoa(end)
+		if end >= len(items) {
+			end = len(items)
+			cursor = ""
+		}
+
+		return fakeResponse{body: items[offset:end], cursor: cursor}
+	}
+}
+
+// staticRoute always answers with body.
+func staticRoute(body interface{}) fakeRoute {
+	return func(_ *http.Request) fakeResponse {
+		return fakeResponse{body: body}
+	}
+}
+
+// statusRoute always fails with the given status.
+func statusRoute(status int) fakeRoute {
+	return func(_ *http.Request) fakeResponse {
+		return fakeResponse{status: status}
+	}
+}
@@ -0,0 +1,270 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/conductorone/baton-onelogin/pkg/onelogin"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+)
+
+// Mapping actions that assign the roles synced by the connector.
+const (
+	mappingActionAddRole  = "add_role"
+	mappingActionSetRole  = "set_role"
+	mappingActionSetGroup = "set_group"
+)
+
+// Type of the added_by of role members added by a mapping.
+const assignerTypeMapping = "mapping"
+
+// Keys of the grant metadata describing how a role grant came to be.
+const (
+	grantSource    = "assignment_source"
+	grantMappingId = "mapping_id"
+
+	grantSourceMapping = "mapping"
+	grantSourceManual  = "manual"
+)
+
+const mappingsCacheTTL = 5 * time.Minute
+
+type mappingResourceType struct {
+	resourceType *v2.ResourceType
+	mappings     *mappingIndex
+}
+
+func (m *mappingResourceType) ResourceType(_ context.Context) *v2.ResourceType {
+	return m.resourceType
+}
+
+// Create a new connector resource for an OneLogin user mapping.
+func mappingResource(mapping *onelogin.Mapping) (*v2.Resource, error) {
+	profile := map[string]interface{}{
+		"mapping_id":      mapping.Id,
+		"mapping_name":    mapping.Name,
+		"mapping_match":   mapping.Match,
+		"mapping_enabled": mapping.Enabled,
+		"conditions":      ruleConditionsProfile(mapping.Conditions),
+		"actions":         ruleActionsProfile(mapping.Actions),
+	}
+	if mapping.Position != nil {
+		profile["mapping_position"] = *mapping.Position
+	}
+
+	resource, err := inventoryResource(
+		mapping.Name,
+		resourceTypeMapping,
+		mapping.Id,
+		profile,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return resource, nil
+}
+
+func (m *mappingResourceType) List(ctx context.Context, _ *v2.ResourceId, _ *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	mappings, err := m.mappings.list(ctx)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	var rv []*v2.Resource
+	for _, mapping := range mappings {
+		mappingCopy := mapping
+		mr, err := mappingResource(&mappingCopy)
+
+		if err != nil {
+			return nil, "", nil, err
+		}
+		addExternalLink(m.mappings.client, mr)
+
+		rv = append(rv, mr)
+	}
+
+	return rv, "", nil, nil
+}
+
+// Mappings assign access rather than grant it, so they have no entitlements or grants of their own.
+func (m *mappingResourceType) Entitlements(_ context.Context, _ *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	return nil, "", nil, nil
+}
+
+func (m *mappingResourceType) Grants(_ context.Context, _ *v2.Resource, _ *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	return nil, "", nil, nil
+}
+
+func mappingBuilder(mappings *mappingIndex) *mappingResourceType {
+	return &mappingResourceType{
+		resourceType: resourceTypeMapping,
+		mappings:     mappings,
+	}
+}
+
+func ruleConditionsProfile(conditions []onelogin.RuleCondition) []interface{} {
+	rv := make([]interface{}, 0, len(conditions))
+	for _, condition := range conditions {
+		rv = append(rv, map[string]interface{}{
+			"source":   condition.Source,
+			"operator": condition.Operator,
+			"value":    condition.Value,
+		})
+	}
+
+	return rv
+}
+
+func ruleActionsProfile(actions []onelogin.RuleAction) []interface{} {
+	rv := make([]interface{}, 0, len(actions))
+	for _, action := range actions {
+		rv = append(rv, map[string]interface{}{
+			"action": action.Action,
+			"value":  stringsToInterfaces(action.Value),
+		})
+	}
+
+	return rv
+}
+
+// mappingIndex caches the user mappings and the roles and groups they assign,
+// so role and group grants can be attributed without listing mappings per role or group.
+type mappingIndex struct {
+	client    *onelogin.Client
+	mutex     sync.Mutex
+	mappings  []onelogin.Mapping
+	roles     map[string][]int
+	groups    map[string][]onelogin.Mapping
+	timestamp time.Time
+}
+
+func newMappingIndex(client *onelogin.Client) *mappingIndex {
+	return &mappingIndex{client: client}
+}
+
+// refresh reloads the mappings if the TTL expired. The caller must hold the mutex.
+func (m *mappingIndex) refresh(ctx context.Context) error {
+	if m.mappings != nil && time.Since(m.timestamp) < mappingsCacheTTL {
+		return nil
+	}
+
+	// mappings the credentials cannot read are treated as none, so role grants are synced as manual
+	mappings, err := m.client.GetMappings(ctx)
+	if err != nil && !skipUnavailable(ctx, err, "mappings") {
+		return fmt.Errorf("onelogin-connector: failed to list mappings: %w", err)
+	}
+
+	roles := make(map[string][]int)
+	groups := make(map[string][]onelogin.Mapping)
+	for _, mapping := range mappings {
+		if !mapping.Enabled {
+			continue
+		}
+
+		for _, action := range mapping.Actions {
+			switch action.Action {
+			case mappingActionAddRole, mappingActionSetRole:
+				for _, roleId := range action.Value {
+					roles[roleId] = append(roles[roleId], mapping.Id)
+				}
+			case mappingActionSetGroup:
+				for _, groupId := range action.Value {
+					groups[groupId] = append(groups[groupId], mapping)
+				}
+			}
+		}
+	}
+
+	if mappings == nil {
+		mappings = []onelogin.Mapping{}
+	}
+
+	m.mappings = mappings
+	m.roles = roles
+	m.groups = groups
+	m.timestamp = time.Now()
+	return nil
+}
+
+func (m *mappingIndex) list(ctx context.Context) ([]onelogin.Mapping, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if err := m.refresh(ctx); err != nil {
+		return nil, err
+	}
+
+	return m.mappings, nil
+}
+
+// roleMappings returns the IDs of the enabled mappings assigning the role.
+func (m *mappingIndex) roleMappings(ctx context.Context, roleId string) ([]int, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if err := m.refresh(ctx); err != nil {
+		return nil, err
+	}
+
+	return m.roles[roleId], nil
+}
+
+// groupMappings returns the enabled mappings setting the group, in the order OneLogin lists them.
+func (m *mappingIndex) groupMappings(ctx context.Context, groupId string) ([]onelogin.Mapping, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if err := m.refresh(ctx); err != nil {
+		return nil, err
+	}
+
+	return m.groups[groupId], nil
+}
+
+// roleGrantSource attributes a role membership to the mapping that added the user, if any of
+// the mappings assigning the role did. User and mapping IDs can collide, so the assigner must be
+// a mapping.
+func roleGrantSource(user *onelogin.UserUnderRole, mappingIds []int) map[string]interface{} {
+	if user.AddedBy != nil && strings.EqualFold(user.AddedBy.Type, assignerTypeMapping) {
+		for _, mappingId := range mappingIds {
+			if mappingId == user.AddedBy.Id {
+				return mappingGrantSource(mappingId)
+			}
+		}
+	}
+
+	return map[string]interface{}{
+		grantSource: grantSourceManual,
+	}
+}
+
+// groupGrantSource attributes a group membership to the first of the mappings setting the group whose
+// conditions the user matches. OneLogin does not record who set a user's group, so the conditions are
+// evaluated against the user attributes like app rules are; a user no mapping matches was set manually.
+func groupGrantSource(user onelogin.UserAttributes, mappings []onelogin.Mapping) map[string]interface{} {
+	for _, mapping := range mappings {
+		// mappings and app rules share their conditions and match type
+		if ruleMatches(onelogin.AppRule(mapping), user) {
+			return mappingGrantSource(mapping.Id)
+		}
+	}
+
+	return map[string]interface{}{
+		grantSource: grantSourceManual,
+	}
+}
+
+func mappingGrantSource(mappingId int) map[string]interface{} {
+	return map[string]interface{}{
+		grantSource:    grantSourceMapping,
+		grantMappingId: strconv.Itoa(mappingId),
+	}
+}
@@ -0,0 +1,369 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/conductorone/baton-onelogin/pkg/onelogin"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	ent "github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+)
+
+const (
+	policyAppliesTo = "applies_to"
+
+	// Key of the grant metadata telling whether a user policy is set on the user or inherited from the group.
+	grantPolicySource = "policy_source"
+
+	policySourceUser    = "user"
+	policySourceGroup   = "group"
+	policySourceDefault = "default"
+	policySourceApp     = "app"
+
+	// user attributes referencing the policies
+	policyUserAttribute = "policy_id"
+	groupUserAttribute  = "group_id"
+)
+
+type policyResourceType struct {
+	resourceType *v2.ResourceType
+	client       *onelogin.Client
+	assignments  *policyAssignmentIndex
+}
+
+func (p *policyResourceType) ResourceType(_ context.Context) *v2.ResourceType {
+	return p.resourceType
+}
+
+// policySettings names the key settings of a policy in the profile, along with the OneLogin settings they
+// are read from. Setting names differ between user and app policies, so the first one present is used.
+var policySettings = []struct {
+	name string
+	keys []string
+}{
+	{name: "mfa_required", keys: []string{"mfa_required", "otp_required", "require_otp"}},
+	{name: "password_min_length", keys: []string{"password_min_length", "min_password_length", "password_length"}},
+	{name: "password_max_age_days", keys: []string{"password_max_age", "password_expiration_days", "password_expiration"}},
+	{name: "session_timeout_minutes", keys: []string{"session_timeout", "session_expiration", "session_timeout_minutes"}},
+	{name: "ip_allowlist", keys: []string{"ip_allowlist", "ip_whitelist", "allowed_ips"}},
+}
+
+// Create a new connector resource for an OneLogin security policy. The key settings are part of the profile
+// under stable names, and all settings are kept as OneLogin returns them as well.
+func policyResource(policy *onelogin.Policy) (*v2.Resource, error) {
+	profile := map[string]interface{}{
+		"policy_id":      policy.Id,
+		"policy_name":    policy.Name,
+		"policy_type":    policy.Type,
+		"policy_default": policy.IsDefault(),
+		"settings":       policy.Settings,
+	}
+
+	for _, setting := range policySettings {
+		for _, key := range setting.keys {
+			if value, ok := policySettingValue(setting.name, policy.Settings[key]); ok {
+				profile[setting.name] = value
+				break
+			}
+		}
+	}
+
+	resource, err := inventoryResource(
+		policy.Name,
+		resourceTypePolicy,
+		policy.Id,
+		profile,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return resource, nil
+}
+
+// policySettingValue normalizes a policy setting, which OneLogin may return as a string, to the type of the
+// profile key. The second result is false if the setting is missing or cannot be read.
+func policySettingValue(name string, value interface{}) (interface{}, bool) {
+	text, isText := value.(string)
+	text = strings.TrimSpace(text)
+
+	switch name {
+	case "mfa_required":
+		if isText {
+			required, err := strconv.ParseBool(text)
+			return required, err == nil
+		}
+		required, ok := value.(bool)
+		return required, ok
+
+	case "ip_allowlist":
+		if !isText {
+			addresses, ok := value.([]interface{})
+			return addresses, ok
+		}
+
+		addresses := []interface{}{}
+		for _, address := range strings.Split(text, ",") {
+			if address = strings.TrimSpace(address); address != "" {
+				addresses = append(addresses, address)
+			}
+		}
+		return addresses, true
+
+	default:
+		if isText {
+			number, err := strconv.ParseFloat(text, 64)
+			return number, err == nil
+		}
+		number, ok := value.(float64)
+		return number, ok
+	}
+}
+
+func (p *policyResourceType) List(ctx context.Context, _ *v2.ResourceId, _ *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	var rv []*v2.Resource
+	for _, policyType := range []string{onelogin.PolicyTypeUser, onelogin.PolicyTypeApp} {
+		policies, err := p.client.GetPolicies(ctx, policyType)
+		if err != nil {
+			if skipUnavailable(ctx, err, policyType+" policies") {
+				continue
+			}
+			return nil, "", nil, fmt.Errorf("onelogin-connector: failed to list %s policies: %w", policyType, err)
+		}
+
+		for _, policy := range policies {
+			policyCopy := policy
+
+			// without the detail, the policy is synced with the settings listed along with it
+			detail, err := p.client.GetPolicy(ctx, strconv.Itoa(policy.Id))
+			if err != nil {
+				if !skipUnavailable(ctx, err, "policy settings") {
+					return nil, "", nil, fmt.Errorf("onelogin-connector: failed to get policy %d: %w", policy.Id, err)
+				}
+				detail = &policyCopy
+			}
+			if detail.Type == "" {
+				detail.Type = policyType
+			}
+
+			pr, err := policyResource(detail)
+
+			if err != nil {
+				return nil, "", nil, err
+			}
+			addExternalLink(p.client, pr)
+
+			rv = append(rv, pr)
+		}
+	}
+
+	return rv, "", nil, nil
+}
+
+func (p *policyResourceType) Entitlements(_ context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	var rv []*v2.Entitlement
+	appliesToOptions := []ent.EntitlementOption{
+		ent.WithGrantableTo(resourceTypeUser, resourceTypeApp),
+		ent.WithDisplayName(fmt.Sprintf("%s Policy %s", resource.DisplayName, policyAppliesTo)),
+		ent.WithDescription(fmt.Sprintf("Governed by %s security policy in OneLogin", resource.DisplayName)),
+	}
+
+	rv = append(
+		rv,
+		ent.NewAssignmentEntitlement(
+			resource,
+			policyAppliesTo,
+			appliesToOptions...,
+		),
+	)
+
+	return rv, "", nil, nil
+}
+
+func (p *policyResourceType) Grants(ctx context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	principals, err := p.assignments.forPolicy(ctx, resource.Id.Resource)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	var rv []*v2.Grant
+	for _, principal := range principals {
+		rv = append(
+			rv,
+			grant.NewGrant(
+				resource,
+				policyAppliesTo,
+				principal.id,
+				grant.WithGrantMetadata(map[string]interface{}{grantPolicySource: principal.source}),
+			),
+		)
+	}
+
+	return rv, "", nil, nil
+}
+
+func policyBuilder(client *onelogin.Client, assignments *policyAssignmentIndex) *policyResourceType {
+	return &policyResourceType{
+		resourceType: resourceTypePolicy,
+		client:       client,
+		assignments:  assignments,
+	}
+}
+
+// policyPrincipal is a user or app governed by a policy, along with where the policy comes from.
+type policyPrincipal struct {
+	id     *v2.ResourceId
+	source string
+}
+
+// policyAssignmentIndex caches the effective policy of every user and app. A user is governed by
+// its own policy, or else by the policy of its group, or else by the account default policy.
+type policyAssignmentIndex struct {
+	client         *onelogin.Client
+	userAttributes *userAttributeIndex
+	mutex          sync.Mutex
+	principals     map[string][]policyPrincipal
+	timestamp      time.Time
+}
+
+func newPolicyAssignmentIndex(client *onelogin.Client, userAttributes *userAttributeIndex) *policyAssignmentIndex {
+	return &policyAssignmentIndex{
+		client:         client,
+		userAttributes: userAttributes,
+	}
+}
+
+// forPolicy returns the users and apps governed by the policy.
+func (p *policyAssignmentIndex) forPolicy(ctx context.Context, policyId string) ([]policyPrincipal, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.principals == nil || time.Since(p.timestamp) >= usersCacheTTL {
+		principals, err := p.load(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		p.principals = principals
+		p.timestamp = time.Now()
+	}
+
+	return p.principals[policyId], nil
+}
+
+func (p *policyAssignmentIndex) load(ctx context.Context) (map[string][]policyPrincipal, error) {
+	groupPolicies := make(map[string]string)
+	cursor := ""
+	for {
+		groups, nextCursor, err := p.client.GetGroups(ctx, onelogin.PaginationVars{
+			Limit:    ResourcesPageSize,
+			V1Cursor: cursor,
+		})
+		if err != nil {
+			if skipUnavailable(ctx, err, "group policies") {
+				break
+			}
+			return nil, fmt.Errorf("onelogin-connector: failed to list groups: %w", err)
+		}
+
+		for _, group := range groups {
+			if group.PolicyId != nil {
+				groupPolicies[strconv.Itoa(group.Id)] = strconv.Itoa(*group.PolicyId)
+			}
+		}
+		if nextCursor == "" {
+			break
+		}
+		cursor = nextCursor
+	}
+
+	defaultPolicyId := ""
+	policies, err := p.client.GetPolicies(ctx, onelogin.PolicyTypeUser)
+	if err != nil && !skipUnavailable(ctx, err, "user policies") {
+		return nil, fmt.Errorf("onelogin-connector: failed to list user policies: %w", err)
+	}
+	for _, policy := range policies {
+		if policy.IsDefault() {
+			defaultPolicyId = strconv.Itoa(policy.Id)
+			break
+		}
+	}
+
+	users, err := p.userAttributes.all(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	userIds := make([]int, 0, len(users))
+	for id := range users {
+		userIds = append(userIds, id)
+	}
+	sort.Ints(userIds)
+
+	rv := make(map[string][]policyPrincipal)
+	for _, id := range userIds {
+		user := users[id]
+		policyId, source := user.Value(policyUserAttribute), policySourceUser
+		if policyId == "" {
+			policyId, source = groupPolicies[user.Value(groupUserAttribute)], policySourceGroup
+		}
+		if policyId == "" {
+			policyId, source = defaultPolicyId, policySourceDefault
+		}
+		if policyId == "" {
+			continue
+		}
+
+		rv[policyId] = append(rv[policyId], policyPrincipal{
+			id: &v2.ResourceId{
+				ResourceType: resourceTypeUser.Id,
+				Resource:     strconv.Itoa(id),
+			},
+			source: source,
+		})
+	}
+
+	cursor = ""
+	for {
+		apps, nextCursor, err := p.client.GetApps(ctx, onelogin.PaginationVars{
+			Limit:  ResourcesPageSize,
+			Cursor: cursor,
+		})
+		if err != nil {
+			if skipUnavailable(ctx, err, "app policies") {
+				break
+			}
+			return nil, fmt.Errorf("onelogin-connector: failed to list apps: %w", err)
+		}
+
+		for _, app := range apps {
+			if app.PolicyId == nil {
+				continue
+			}
+
+			policyId := strconv.Itoa(*app.PolicyId)
+			rv[policyId] = append(rv[policyId], policyPrincipal{
+				id: &v2.ResourceId{
+					ResourceType: resourceTypeApp.Id,
+					Resource:     strconv.Itoa(app.Id),
+				},
+				source: policySourceApp,
+			})
+		}
+		if nextCursor == "" {
+			break
+		}
+		cursor = nextCursor
+	}
+
+	return rv, nil
+}
@@ -0,0 +1,138 @@
+package connector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/conductorone/baton-onelogin/pkg/onelogin"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+)
+
+func TestPolicyResourceKeySettings(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings map[string]interface{}
+		want     map[string]interface{}
+	}{
+		{
+			name: "typed settings",
+			settings: map[string]interface{}{
+				"otp_required":        true,
+				"password_min_length": float64(12),
+				"password_expiration": float64(90),
+				"session_timeout":     float64(480),
+				"ip_whitelist":        []interface{}{"10.0.0.0/8"},
+			},
+			want: map[string]interface{}{
+				"mfa_required":            true,
+				"password_min_length":     float64(12),
+				"password_max_age_days":   float64(90),
+				"session_timeout_minutes": float64(480),
+				"ip_allowlist":            []interface{}{"10.0.0.0/8"},
+			},
+		},
+		{
+			name: "settings returned as strings",
+			settings: map[string]interface{}{
+				"mfa_required":    "false",
+				"password_length": "8",
+				"ip_allowlist":    "10.0.0.1, 10.0.0.2",
+			},
+			want: map[string]interface{}{
+				"mfa_required":        false,
+				"password_min_length": float64(8),
+				"ip_allowlist":        []interface{}{"10.0.0.1", "10.0.0.2"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resource, err := policyResource(&onelogin.Policy{
+				BaseResource: onelogin.BaseResource{Id: 1},
+				Name:         "Strict",
+				Type:         onelogin.PolicyTypeUser,
+				Settings:     tt.settings,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			profile := resourceProfile(t, resource)
+			for _, setting := range policySettings {
+				want, wantOk := tt.want[setting.name]
+				got, ok := profile[setting.name]
+				if ok != wantOk {
+					t.Fatalf("%s: expected set: %v, got %v", setting.name, wantOk, got)
+				}
+				if !wantOk {
+					continue
+				}
+
+				if list, isList := want.([]interface{}); isList {
+					gotList, _ := got.([]interface{})
+					if len(gotList) != len(list) {
+						t.Fatalf("%s: expected %v, got %v", setting.name, want, got)
+					}
+					for i := range list {
+						if gotList[i] != list[i] {
+							t.Fatalf("%s: expected %v, got %v", setting.name, want, got)
+						}
+					}
+					continue
+				}
+				if got != want {
+					t.Fatalf("%s: expected %v, got %v", setting.name, want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestPolicyGrantsCoverEveryUser(t *testing.T) {
+	api := newFakeOneLogin(map[string]fakeRoute{
+		"GET /api/2/policies": staticRoute([]map[string]interface{}{
+			{"id": 1, "name": "Default policy"},
+			{"id": 2, "name": "Strict"},
+			{"id": 3, "name": "Contractors"},
+		}),
+		"GET /api/1/groups": staticRoute(map[string]interface{}{
+			"data": []map[string]interface{}{{"id": 7, "name": "Contractors", "policy_id": 3}},
+		}),
+		"GET /api/2/users": staticRoute([]map[string]interface{}{
+			{"id": 10, "policy_id": 2},
+			{"id": 11, "group_id": 7},
+			{"id": 12},
+		}),
+		"GET /api/2/apps": staticRoute([]onelogin.App{}),
+	})
+	client := api.client(t)
+	p := policyBuilder(client, newPolicyAssignmentIndex(client, newUserAttributeIndex(client)))
+
+	want := map[string]map[string]string{
+		"1": {"12": policySourceDefault},
+		"2": {"10": policySourceUser},
+		"3": {"11": policySourceGroup},
+	}
+
+	for policyId, wantUsers := range want {
+		policy := &v2.Resource{Id: &v2.ResourceId{ResourceType: resourceTypePolicy.Id, Resource: policyId}}
+		grants, _, _, err := p.Grants(context.Background(), policy, &pagination.Token{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(grants) != len(wantUsers) {
+			t.Fatalf("policy %s: expected %d grants, got %d", policyId, len(wantUsers), len(grants))
+		}
+		for _, g := range grants {
+			userId := g.Principal.Id.Resource
+			source := annotationMetadata(t, annotations.Annotations(g.Annotations))[grantPolicySource]
+			if source != wantUsers[userId] {
+				t.Fatalf("policy %s, user %s: expected source %s, got %v", policyId, userId, wantUsers[userId], source)
+			}
+		}
+	}
+}
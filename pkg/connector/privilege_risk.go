@@ -0,0 +1,212 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/conductorone/baton-onelogin/pkg/onelogin"
+	ent "github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// Privilege levels of a role, from the most to the least powerful.
+const (
+	privilegeLevelSuperUser = "super_user"
+	privilegeLevelUserAdmin = "user_admin"
+	privilegeLevelAppAdmin  = "app_admin"
+	privilegeLevelReadOnly  = "read_only"
+	privilegeLevelNone      = "none"
+
+	// privilegeLevelUnknown is reported when the privileges could not be read, e.g. on tenants
+	// without delegated administration.
+	privilegeLevelUnknown = "unknown"
+)
+
+var privilegeLevelRank = map[string]int{
+	privilegeLevelNone:      0,
+	privilegeLevelReadOnly:  1,
+	privilegeLevelAppAdmin:  2,
+	privilegeLevelUserAdmin: 3,
+	privilegeLevelSuperUser: 4,
+}
+
+// Keys of the entitlement metadata flagging roles whose privileges allow administration.
+// The SDK version in use has no risk annotation, so it is reported the same way grant metadata is.
+const (
+	entitlementPrivilegeLevel = "privilege_level"
+	entitlementAdminCapable   = "admin_capable"
+)
+
+// Action verbs that only read data, e.g. users:List or reports:Get.
+var readOnlyVerbs = []string{"list", "get", "read", "view", "search"}
+
+// classifyPrivilege returns the privilege level granted by the allowed actions of a privilege.
+// Any action on everything, or changing roles or privileges, makes a super user, as those allow
+// granting any other privilege. Changes to apps make an app admin, and any other change a user admin.
+func classifyPrivilege(privilege *onelogin.Privilege) string {
+	level := privilegeLevelNone
+	for _, statement := range privilege.Privilege.Statement {
+		if !strings.EqualFold(statement.Effect, "allow") {
+			continue
+		}
+
+		for _, action := range statement.Action {
+			level = maxPrivilegeLevel(level, classifyAction(action))
+		}
+	}
+
+	return level
+}
+
+func classifyAction(action string) string {
+	namespace, verb, _ := strings.Cut(strings.ToLower(action), ":")
+	if namespace == "*" {
+		return privilegeLevelSuperUser
+	}
+
+	for _, readOnly := range readOnlyVerbs {
+		if strings.HasPrefix(verb, readOnly) {
+			return privilegeLevelReadOnly
+		}
+	}
+
+	switch namespace {
+	case "roles", "privileges":
+		return privilegeLevelSuperUser
+	case "apps":
+		return privilegeLevelAppAdmin
+	default:
+		return privilegeLevelUserAdmin
+	}
+}
+
+func maxPrivilegeLevel(a, b string) string {
+	if privilegeLevelRank[b] > privilegeLevelRank[a] {
+		return b
+	}
+
+	return a
+}
+
+func isAdminCapable(level string) bool {
+	return privilegeLevelRank[level] >= privilegeLevelRank[privilegeLevelAppAdmin]
+}
+
+// roleClassification is the privilege level of a role along with the privileges it holds.
+type roleClassification struct {
+	level      string
+	privileges []string
+}
+
+func (c *roleClassification) describe() string {
+	if c.level == privilegeLevelUnknown {
+		return "unknown privileges"
+	}
+
+	if len(c.privileges) == 0 {
+		return "no privileges"
+	}
+
+	return fmt.Sprintf("%s privileges (%s)", strings.ReplaceAll(c.level, "_", " "), strings.Join(c.privileges, ", "))
+}
+
+// entitlementOptions flags the entitlements of admin-capable roles.
+func (c *roleClassification) entitlementOptions() ([]ent.EntitlementOption, error) {
+	if !isAdminCapable(c.level) {
+		return nil, nil
+	}
+
+	md, err := structpb.NewStruct(map[string]interface{}{
+		entitlementPrivilegeLevel: c.level,
+		entitlementAdminCapable:   true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return []ent.EntitlementOption{ent.WithAnnotation(md)}, nil
+}
+
+// privilegesCacheTTL is how long the privileges assigned to roles are cached.
+const privilegesCacheTTL = 5 * time.Minute
+
+// rolePrivilegeIndex caches the privileges assigned to each role.
+type rolePrivilegeIndex struct {
+	client    *onelogin.Client
+	mutex     sync.Mutex
+	roles     map[string]*roleClassification
+	timestamp time.Time
+}
+
+func newRolePrivilegeIndex(client *onelogin.Client) *rolePrivilegeIndex {
+	return &rolePrivilegeIndex{client: client}
+}
+
+// classify returns the privilege level of the role. The classification is optional, so privileges
+// that cannot be read are logged and every role is reported with an unknown level until the next refresh.
+func (r *rolePrivilegeIndex) classify(ctx context.Context, roleId string) *roleClassification {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.timestamp.IsZero() || time.Since(r.timestamp) >= privilegesCacheTTL {
+		roles, err := r.load(ctx)
+		if err != nil {
+			ctxzap.Extract(ctx).Warn("onelogin-connector: failed to classify roles, privilege levels are not available", zap.Error(err))
+		}
+
+		r.roles = roles
+		r.timestamp = time.Now()
+	}
+
+	if r.roles == nil {
+		return &roleClassification{level: privilegeLevelUnknown}
+	}
+
+	if classification, ok := r.roles[roleId]; ok {
+		return classification
+	}
+
+	return &roleClassification{level: privilegeLevelNone}
+}
+
+func (r *rolePrivilegeIndex) load(ctx context.Context) (map[string]*roleClassification, error) {
+	privileges, err := r.client.GetPrivileges(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("onelogin-connector: failed to list privileges: %w", err)
+	}
+
+	rv := make(map[string]*roleClassification)
+	for i := range privileges {
+		privilege := &privileges[i]
+
+		roleIds, err := r.client.GetPrivilegeRoles(ctx, privilege.Id)
+		if err != nil {
+			return nil, fmt.Errorf("onelogin-connector: failed to list roles under privilege %s: %w", privilege.Id, err)
+		}
+
+		level := classifyPrivilege(privilege)
+		for _, roleId := range roleIds {
+			classification, ok := rv[strconv.Itoa(roleId)]
+			if !ok {
+				classification = &roleClassification{level: privilegeLevelNone}
+				rv[strconv.Itoa(roleId)] = classification
+			}
+
+			classification.level = maxPrivilegeLevel(classification.level, level)
+			classification.privileges = append(classification.privileges, privilege.Name)
+		}
+	}
+
+	for _, classification := range rv {
+		sort.Strings(classification.privileges)
+	}
+
+	return rv, nil
+}
@@ -0,0 +1,93 @@
+package connector
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/conductorone/baton-onelogin/pkg/onelogin"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+)
+
+func roleTraitProfile(t *testing.T, resource *v2.Resource) map[string]interface{} {
+	t.Helper()
+
+	for _, annotation := range resource.Annotations {
+		trait := &v2.RoleTrait{}
+		if annotation.MessageIs(trait) {
+			err := annotation.UnmarshalTo(trait)
+			if err != nil {
+				t.Fatalf("failed to read role trait: %v", err)
+			}
+			return trait.GetProfile().AsMap()
+		}
+	}
+
+	t.Fatalf("role %s has no trait", resource.Id.Resource)
+	return nil
+}
+
+func TestRoleListClassifiesPrivileges(t *testing.T) {
+	privileges := []onelogin.Privilege{
+		{
+			Id:   "p1",
+			Name: "Manage roles",
+			Privilege: onelogin.PrivilegeDocument{Statement: []onelogin.PrivilegeStatement{
+				{Effect: "Allow", Action: []string{"roles:Update"}},
+			}},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		privileges fakeRoute
+		wantLevels map[string]string
+	}{
+		{
+			name:       "privileges are read",
+			privileges: staticRoute(privileges),
+			wantLevels: map[string]string{"10": privilegeLevelSuperUser, "11": privilegeLevelNone},
+		},
+		{
+			name:       "privileges are forbidden",
+			privileges: statusRoute(http.StatusForbidden),
+			wantLevels: map[string]string{"10": privilegeLevelUnknown, "11": privilegeLevelUnknown},
+		},
+		{
+			name:       "delegated administration is unavailable",
+			privileges: statusRoute(http.StatusNotFound),
+			wantLevels: map[string]string{"10": privilegeLevelUnknown, "11": privilegeLevelUnknown},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakeOneLogin(map[string]fakeRoute{
+				"GET /api/2/roles": staticRoute([]onelogin.Role{
+					{BaseResource: onelogin.BaseResource{Id: 10}, Name: "Role admins"},
+					{BaseResource: onelogin.BaseResource{Id: 11}, Name: "Support"},
+				}),
+				"GET /api/2/privileges":          tt.privileges,
+				"GET /api/2/privileges/p1/roles": staticRoute(map[string][]int{"roles": {10}}),
+			})
+			client := api.client(t)
+			r := roleBuilder(client, &provisioning{}, nil, newRolePrivilegeIndex(client))
+
+			roles, _, _, err := r.List(context.Background(), nil, &pagination.Token{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(roles) != len(tt.wantLevels) {
+				t.Fatalf("expected %d roles, got %d", len(tt.wantLevels), len(roles))
+			}
+			for _, role := range roles {
+				level := roleTraitProfile(t, role)["privilege_level"]
+				if level != tt.wantLevels[role.Id.Resource] {
+					t.Fatalf("role %s: expected level %s, got %v", role.Id.Resource, tt.wantLevels[role.Id.Resource], level)
+				}
+			}
+		})
+	}
+}
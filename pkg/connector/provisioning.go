@@ -0,0 +1,389 @@
+package connector
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/conductorone/baton-onelogin/pkg/expiry"
+	"github.com/conductorone/baton-onelogin/pkg/journal"
+	"github.com/conductorone/baton-onelogin/pkg/onelogin"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const (
+	verifyInitialBackoff = 500 * time.Millisecond
+	verifyMaxBackoff     = 8 * time.Second
+
+	// Keys of the metadata returned from provisioning once the change was confirmed.
+	confirmedAt          = "confirmed_at"
+	confirmationDuration = "confirmation_duration"
+
+	// Keys of the metadata returned from provisioning in dry-run mode.
+	dryRun       = "dry_run"
+	dryRunAction = "action"
+)
+
+// ProvisioningPolicy restricts what Grant and Revoke are allowed to change in OneLogin.
+type ProvisioningPolicy struct {
+	// ProtectedRoles holds IDs or names of roles that can never be granted or revoked.
+	ProtectedRoles []string
+	// ProtectedApps holds IDs or names of apps; roles carrying any of them are protected.
+	ProtectedApps []string
+	// ProtectedEntitlements holds entitlement slugs (e.g. "admin") or full entitlement IDs.
+	ProtectedEntitlements []string
+	// OverrideTokenHash is the hex encoded SHA-256 of the token that unlocks protected entitlements.
+	OverrideTokenHash string
+	// OverrideToken is supplied for a single run to unlock protected entitlements.
+	OverrideToken string
+	// MaxAdminGrants caps the number of admin grants per run, zero means no cap.
+	MaxAdminGrants int
+	// DryRun logs and returns the changes that would be made without calling the API.
+	DryRun bool
+}
+
+// provisioning holds the provisioning settings and the per-run state shared by the provisioners.
+type provisioning struct {
+	verifyTimeout time.Duration
+	policy        ProvisioningPolicy
+	journal       *journal.Journal
+	expiries      *expiry.Store
+	defaultExpiry string
+	batchWindow   time.Duration
+	batcher       *onelogin.RoleBatcher
+
+	mutex       sync.Mutex
+	adminGrants int
+}
+
+// overridden reports whether a valid override token was supplied for this run.
+func (p *provisioning) overridden() bool {
+	if p.policy.OverrideToken == "" || p.policy.OverrideTokenHash == "" {
+		return false
+	}
+
+	sum := sha256.Sum256([]byte(p.policy.OverrideToken))
+	return subtle.ConstantTimeCompare([]byte(hex.EncodeToString(sum[:])), []byte(p.policy.OverrideTokenHash)) == 1
+}
+
+// checkPolicy returns an error if the provisioning policy protects the entitlement or the role it belongs to.
+func (p *provisioning) checkPolicy(ctx context.Context, client *onelogin.Client, entitlement *v2.Entitlement) error {
+	reason, err := p.protectionReason(ctx, client, entitlement)
+	if err != nil {
+		return err
+	}
+
+	return p.enforce(ctx, "entitlement", entitlement.Id, reason)
+}
+
+// checkRolePolicy returns an error if the provisioning policy protects the role, or the name the role would
+// be created or renamed with. roleId is empty for a role about to be created.
+func (p *provisioning) checkRolePolicy(ctx context.Context, client *onelogin.Client, roleId, name string) error {
+	var err error
+	reason := p.protectedRole("", name)
+	if reason == "" && roleId != "" {
+		reason, err = p.roleProtectionReason(ctx, client, roleId)
+		if err != nil {
+			return err
+		}
+	}
+
+	subject := roleId
+	if subject == "" {
+		subject = name
+	}
+
+	return p.enforce(ctx, "role", subject, reason)
+}
+
+// checkPrivilegePolicy returns an error if the provisioning policy protects the privilege entitlement,
+// or the role it would be assigned to or removed from.
+func (p *provisioning) checkPrivilegePolicy(ctx context.Context, client *onelogin.Client, entitlement *v2.Entitlement, principal *v2.Resource) error {
+	reason, err := p.protectionReason(ctx, client, entitlement)
+	if err != nil {
+		return err
+	}
+
+	if reason == "" && principal.Id.ResourceType == resourceTypeRole.Id {
+		reason, err = p.roleProtectionReason(ctx, client, principal.Id.Resource)
+		if err != nil {
+			return err
+		}
+	}
+
+	return p.enforce(ctx, "entitlement", entitlement.Id, reason)
+}
+
+// enforce refuses the change of the entitlement or role for the given protection reason,
+// unless a valid override token was supplied.
+func (p *provisioning) enforce(ctx context.Context, kind, id, reason string) error {
+	if reason == "" {
+		return nil
+	}
+
+	if p.overridden() {
+		ctxzap.Extract(ctx).Warn(
+			"onelogin-connector: protected change made with override token",
+			zap.String(kind+"_id", id),
+			zap.String("reason", reason),
+		)
+		return nil
+	}
+
+	return fmt.Errorf("%s %s is protected by provisioning policy: %s", kind, id, reason)
+}
+
+func (p *provisioning) protectionReason(ctx context.Context, client *onelogin.Client, entitlement *v2.Entitlement) (string, error) {
+	for _, protected := range p.policy.ProtectedEntitlements {
+		if protected == entitlement.Slug || protected == entitlement.Id {
+			return fmt.Sprintf("protected entitlement %s", protected), nil
+		}
+	}
+
+	if entitlement.Resource.Id.ResourceType != resourceTypeRole.Id {
+		return "", nil
+	}
+
+	return p.roleProtectionReason(ctx, client, entitlement.Resource.Id.Resource)
+}
+
+// roleProtectionReason returns why the role is protected, if it is, by name or by the apps it carries.
+// The name is read from OneLogin, as the one a request carries is not to be trusted.
+func (p *provisioning) roleProtectionReason(ctx context.Context, client *onelogin.Client, roleId string) (string, error) {
+	if len(p.policy.ProtectedRoles) > 0 {
+		role, err := client.GetRole(ctx, roleId)
+		if err != nil {
+			return "", fmt.Errorf("failed to get role %s: %w", roleId, err)
+		}
+
+		if reason := p.protectedRole(roleId, role.Name); reason != "" {
+			return reason, nil
+		}
+	}
+
+	if len(p.policy.ProtectedApps) == 0 {
+		return "", nil
+	}
+
+	cursor := ""
+	for {
+		roleApps, nextCursor, err := client.GetRoleApps(ctx, roleId, onelogin.PaginationVars{
+			Limit:  ResourcesPageSize,
+			Cursor: cursor,
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to list apps under role %s: %w", roleId, err)
+		}
+
+		for _, app := range roleApps {
+			for _, protected := range p.policy.ProtectedApps {
+				if protected == strconv.Itoa(app.Id) || protected == app.Name {
+					return fmt.Sprintf("role carries protected app %s", protected), nil
+				}
+			}
+		}
+
+		if nextCursor == "" {
+			return "", nil
+		}
+		cursor = nextCursor
+	}
+}
+
+// protectedRole returns why a role with the given ID and name is protected by the protected roles, if it is.
+// An empty ID or name is not compared.
+func (p *provisioning) protectedRole(roleId, name string) string {
+	for _, protected := range p.policy.ProtectedRoles {
+		if (roleId != "" && protected == roleId) || (name != "" && protected == name) {
+			return fmt.Sprintf("protected role %s", protected)
+		}
+	}
+
+	return ""
+}
+
+// reserveAdminGrant counts an admin grant against the per run cap.
+func (p *provisioning) reserveAdminGrant() error {
+	if p.policy.MaxAdminGrants <= 0 {
+		return nil
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.adminGrants >= p.policy.MaxAdminGrants {
+		return fmt.Errorf("admin grant cap of %d per run reached", p.policy.MaxAdminGrants)
+	}
+	p.adminGrants++
+
+	return nil
+}
+
+// releaseAdminGrant gives back an admin grant reserved for a grant that failed.
+func (p *provisioning) releaseAdminGrant() {
+	if p.policy.MaxAdminGrants <= 0 {
+		return
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.adminGrants--
+}
+
+// record appends the outcome of a provisioning action to the journal, if one is configured.
+// A failure to write the journal is logged but never undoes or fails the action itself.
+func (p *provisioning) record(
+	ctx context.Context,
+	action string,
+	principal *v2.ResourceId,
+	entitlementId string,
+	resourceId *v2.ResourceId,
+	recorder *onelogin.ResponseRecorder,
+	annos annotations.Annotations,
+	actionErr error,
+) {
+	if p.journal == nil {
+		return
+	}
+
+	l := ctxzap.Extract(ctx)
+
+	entry := journal.Entry{
+		Action:        action,
+		Entitlement:   entitlementId,
+		PayloadSHA256: recorder.PayloadSHA256,
+		HTTPStatus:    recorder.StatusCode,
+		RequestId:     recorder.RequestId,
+		Outcome:       journalOutcome(annos, actionErr),
+	}
+	if principal != nil {
+		entry.Principal = fmt.Sprintf("%s:%s", principal.ResourceType, principal.Resource)
+	}
+	if resourceId != nil {
+		entry.Resource = fmt.Sprintf("%s:%s", resourceId.ResourceType, resourceId.Resource)
+	}
+	if actionErr != nil {
+		entry.Error = actionErr.Error()
+	}
+
+	err := p.journal.Append(entry)
+	if err != nil {
+		l.Error(
+			"onelogin-connector: failed to write provisioning journal",
+			zap.String("action", action),
+			zap.String("entitlement", entitlementId),
+			zap.Error(err),
+		)
+	}
+}
+
+func journalOutcome(annos annotations.Annotations, err error) string {
+	if err != nil {
+		return journal.OutcomeFailed
+	}
+
+	metadata := &structpb.Struct{}
+	ok, pickErr := annos.Pick(metadata)
+	if pickErr != nil || !ok {
+		return journal.OutcomeSucceeded
+	}
+
+	fields := metadata.GetFields()
+	switch {
+	case fields[grantAlreadyExists].GetBoolValue():
+		return journal.OutcomeAlreadyExists
+	case fields[grantAlreadyRevoked].GetBoolValue():
+		return journal.OutcomeAlreadyRevoked
+	case fields[dryRun].GetBoolValue():
+		return journal.OutcomeDryRun
+	default:
+		return journal.OutcomeSucceeded
+	}
+}
+
+// dryRunMetadata describes the change that would have been made outside of dry-run mode.
+func dryRunMetadata(action string) map[string]interface{} {
+	return map[string]interface{}{
+		dryRun:       true,
+		dryRunAction: action,
+	}
+}
+
+// grantRole assigns the role entitlement to the user, through the batcher when batching is enabled.
+func (p *provisioning) grantRole(ctx context.Context, client *onelogin.Client, roleId, userId, entitlement string) error {
+	if p.batcher != nil {
+		return p.batcher.GrantRole(ctx, roleId, userId, entitlement)
+	}
+
+	return client.GrantRole(ctx, roleId, userId, entitlement)
+}
+
+// revokeRole removes the role entitlement from the user, through the batcher when batching is enabled.
+func (p *provisioning) revokeRole(ctx context.Context, client *onelogin.Client, roleId, userId, entitlement string) error {
+	if p.batcher != nil {
+		return p.batcher.RevokeRole(ctx, roleId, userId, entitlement)
+	}
+
+	return client.RevokeRole(ctx, roleId, userId, entitlement)
+}
+
+// waitForRoleState polls the role users or admins with a bounded backoff until the membership of the user matches
+// hasRole. It returns the time the change was first observed, or an error once the verification timeout elapses.
+func (r *roleResourceType) waitForRoleState(ctx context.Context, roleId, userId, entitlement string, hasRole bool) (time.Time, error) {
+	deadline := time.Now().Add(r.provisioning.verifyTimeout)
+	backoff := verifyInitialBackoff
+
+	for {
+		current, err := r.userHasRole(ctx, roleId, userId, entitlement)
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		if current == hasRole {
+			return time.Now(), nil
+		}
+
+		wait := time.Until(deadline)
+		if wait <= 0 {
+			return time.Time{}, fmt.Errorf(
+				"%s role %s for user %s was accepted by OneLogin but is not visible after %s",
+				entitlement,
+				roleId,
+				userId,
+				r.provisioning.verifyTimeout,
+			)
+		}
+		if backoff < wait {
+			wait = backoff
+		}
+
+		select {
+		case <-ctx.Done():
+			return time.Time{}, ctx.Err()
+		case <-time.After(wait):
+		}
+
+		backoff *= 2
+		if backoff > verifyMaxBackoff {
+			backoff = verifyMaxBackoff
+		}
+	}
+}
+
+// verificationMetadata describes when a verified change became visible.
+func verificationMetadata(started, confirmed time.Time) map[string]interface{} {
+	return map[string]interface{}{
+		confirmedAt:          confirmed.UTC().Format(time.RFC3339),
+		confirmationDuration: confirmed.Sub(started).String(),
+	}
+}
@@ -0,0 +1,177 @@
+package connector
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/conductorone/baton-onelogin/pkg/onelogin"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	ent "github.com/conductorone/baton-sdk/pkg/types/entitlement"
+)
+
+func TestRoleProtectionReadsRoleName(t *testing.T) {
+	tests := []struct {
+		name          string
+		displayName   string
+		roleName      string
+		wantProtected bool
+	}{
+		{name: "protected role under another name", displayName: "Support", roleName: "Super admins", wantProtected: true},
+		{name: "unprotected role under a protected name", displayName: "Super admins", roleName: "Support"},
+		{name: "unprotected role", displayName: "Support", roleName: "Support"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakeOneLogin(map[string]fakeRoute{
+				"GET /api/2/roles/10": staticRoute(onelogin.Role{BaseResource: onelogin.BaseResource{Id: 10}, Name: tt.roleName}),
+			})
+			p := &provisioning{policy: ProvisioningPolicy{ProtectedRoles: []string{"Super admins"}}}
+
+			entitlement := roleMemberEntitlement()
+			entitlement.Resource.DisplayName = tt.displayName
+
+			err := p.checkPolicy(context.Background(), api.client(t), entitlement)
+			if (err != nil) != tt.wantProtected {
+				t.Fatalf("expected protected: %v, got error %v", tt.wantProtected, err)
+			}
+		})
+	}
+}
+
+func TestAdminGrantCapReleasedOnFailure(t *testing.T) {
+	var mutex sync.Mutex
+	failures := 1
+	api := newFakeOneLogin(map[string]fakeRoute{
+		"GET /api/2/roles/10/admins": staticRoute([]onelogin.UserUnderRole{}),
+		"POST /api/2/roles/10/admins": func(_ *http.Request) fakeResponse {
+			mutex.Lock()
+			defer mutex.Unlock()
+			if failures > 0 {
+				failures--
+				return fakeResponse{status: http.StatusInternalServerError}
+			}
+			return fakeResponse{body: []interface{}{}}
+		},
+	})
+	r := roleBuilder(api.client(t), &provisioning{policy: ProvisioningPolicy{MaxAdminGrants: 1}}, nil, nil)
+
+	role := &v2.Resource{Id: roleResourceId("10")}
+	entitlement := &v2.Entitlement{Id: ent.NewEntitlementID(role, roleAdmin), Resource: role, Slug: roleAdmin}
+
+	_, err := r.Grant(context.Background(), userPrincipal("1"), entitlement)
+	if err == nil {
+		t.Fatalf("expected the first grant to fail")
+	}
+
+	// the failed grant gave its slot back, so the cap still allows one admin grant
+	_, err = r.Grant(context.Background(), userPrincipal("1"), entitlement)
+	if err != nil {
+		t.Fatalf("expected the retried grant to succeed, got %v", err)
+	}
+
+	_, err = r.Grant(context.Background(), userPrincipal("2"), entitlement)
+	if err == nil {
+		t.Fatalf("expected the admin grant cap to be reached")
+	}
+}
+
+func TestRoleLifecycleFollowsProvisioningPolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		roleName string
+		dryRun   bool
+		change   func(ctx context.Context, r *roleResourceType) error
+		route    string
+		wantErr  bool
+	}{
+		{
+			name:     "force does not delete a protected role",
+			roleName: "Super admins",
+			change:   func(ctx context.Context, r *roleResourceType) error { return r.Delete(ctx, "10", true) },
+			route:    "DELETE /api/2/roles/10",
+			wantErr:  true,
+		},
+		{
+			name:     "dry run does not delete a role",
+			roleName: "Support",
+			dryRun:   true,
+			change:   func(ctx context.Context, r *roleResourceType) error { return r.Delete(ctx, "10", true) },
+			route:    "DELETE /api/2/roles/10",
+		},
+		{
+			name:     "protected role cannot be renamed away",
+			roleName: "Super admins",
+			change: func(ctx context.Context, r *roleResourceType) error {
+				_, err := r.Rename(ctx, "10", "Support")
+				return err
+			},
+			route:   "PUT /api/2/roles/10",
+			wantErr: true,
+		},
+		{
+			name:     "role cannot be renamed to a protected name",
+			roleName: "Support",
+			change: func(ctx context.Context, r *roleResourceType) error {
+				_, err := r.Rename(ctx, "10", "Super admins")
+				return err
+			},
+			route:   "PUT /api/2/roles/10",
+			wantErr: true,
+		},
+		{
+			name:     "dry run does not rename a role",
+			roleName: "Support",
+			dryRun:   true,
+			change: func(ctx context.Context, r *roleResourceType) error {
+				_, err := r.Rename(ctx, "10", "Helpdesk")
+				return err
+			},
+			route: "PUT /api/2/roles/10",
+		},
+		{
+			name: "role cannot be created with a protected name",
+			change: func(ctx context.Context, r *roleResourceType) error {
+				_, err := r.Create(ctx, &onelogin.RoleBody{Name: "Super admins"})
+				return err
+			},
+			route:   "POST /api/2/roles",
+			wantErr: true,
+		},
+		{
+			name:   "dry run does not create a role",
+			dryRun: true,
+			change: func(ctx context.Context, r *roleResourceType) error {
+				_, err := r.Create(ctx, &onelogin.RoleBody{Name: "Helpdesk"})
+				return err
+			},
+			route: "POST /api/2/roles",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakeOneLogin(map[string]fakeRoute{
+				"GET /api/2/roles/10":      staticRoute(onelogin.Role{BaseResource: onelogin.BaseResource{Id: 10}, Name: tt.roleName}),
+				"GET /api/2/roles/10/apps": staticRoute([]onelogin.App{}),
+				"DELETE /api/2/roles/10":   statusRoute(http.StatusNoContent),
+				"PUT /api/2/roles/10":      staticRoute(onelogin.BaseResource{Id: 10}),
+				"POST /api/2/roles":        staticRoute([]onelogin.BaseResource{{Id: 11}}),
+			})
+			r := roleBuilder(api.client(t), &provisioning{policy: ProvisioningPolicy{
+				ProtectedRoles: []string{"Super admins"},
+				DryRun:         tt.dryRun,
+			}}, nil, nil)
+
+			err := tt.change(context.Background(), r)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error: %v, got %v", tt.wantErr, err)
+			}
+			if calls := api.called(tt.route); calls != 0 {
+				t.Fatalf("expected no %s request, got %d", tt.route, calls)
+			}
+		})
+	}
+}
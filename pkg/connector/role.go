@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"time"
 
+	"github.com/conductorone/baton-onelogin/pkg/expiry"
 	"github.com/conductorone/baton-onelogin/pkg/onelogin"
 	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
 	"github.com/conductorone/baton-sdk/pkg/annotations"
@@ -26,6 +28,9 @@ const (
 type roleResourceType struct {
 	resourceType *v2.ResourceType
 	client       *onelogin.Client
+	provisioning *provisioning
+	mappings     *mappingIndex
+	privileges   *rolePrivilegeIndex
 }
 
 func (r *roleResourceType) ResourceType(_ context.Context) *v2.ResourceType {
@@ -33,11 +38,15 @@ func (r *roleResourceType) ResourceType(_ context.Context) *v2.ResourceType {
 }
 
 // Create a new connector resource for an OneLogin Role.
-func roleResource(role *onelogin.Role) (*v2.Resource, error) {
+func roleResource(role *onelogin.Role, classification *roleClassification) (*v2.Resource, error) {
 	profile := map[string]interface{}{
 		"role_name": role.Name,
 		"role_id":   role.Id,
 	}
+	if classification != nil {
+		profile["privilege_level"] = classification.level
+		profile["privileges"] = stringsToInterfaces(classification.privileges)
+	}
 
 	roleTraitOptions := []rs.RoleTraitOption{
 		rs.WithRoleProfile(profile),
@@ -82,11 +91,13 @@ func (r *roleResourceType) List(ctx context.Context, _ *v2.ResourceId, pt *pagin
 	var rv []*v2.Resource
 	for _, role := range roles {
 		roleCopy := role
-		rr, err := roleResource(&roleCopy)
+		classification := r.privileges.classify(ctx, strconv.Itoa(role.Id))
+		rr, err := roleResource(&roleCopy, classification)
 
 		if err != nil {
 			return nil, "", nil, err
 		}
+		addExternalLink(r.client, rr)
 
 		rv = append(rv, rr)
 	}
@@ -94,20 +105,29 @@ func (r *roleResourceType) List(ctx context.Context, _ *v2.ResourceId, pt *pagin
 	return rv, nextPage, nil, nil
 }
 
-func (r *roleResourceType) Entitlements(_ context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+func (r *roleResourceType) Entitlements(ctx context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
 	var rv []*v2.Entitlement
 
+	classification := r.privileges.classify(ctx, resource.Id.Resource)
+	riskOptions, err := classification.entitlementOptions()
+	if err != nil {
+		return nil, "", nil, err
+	}
+
 	memberAssignmentOptions := []ent.EntitlementOption{
-		ent.WithGrantableTo(resourceTypeUser, resourceTypeApp),
+		ent.WithGrantableTo(resourceTypeUser),
 		ent.WithDisplayName(fmt.Sprintf("%s Role %s", resource.DisplayName, roleMembership)),
-		ent.WithDescription(fmt.Sprintf("Access to %s role in OneLogin", resource.DisplayName)),
+		ent.WithDescription(fmt.Sprintf("Access to %s role in OneLogin, carrying %s", resource.DisplayName, classification.describe())),
 	}
+	memberAssignmentOptions = append(memberAssignmentOptions, riskOptions...)
 
 	adminAssignmentOptions := []ent.EntitlementOption{
-		ent.WithGrantableTo(resourceTypeUser, resourceTypeApp),
+		ent.WithGrantableTo(resourceTypeUser),
 		ent.WithDisplayName(fmt.Sprintf("%s Role %s", resource.DisplayName, roleAdmin)),
 		ent.WithDescription(fmt.Sprintf("Admin access to %s role in OneLogin", resource.DisplayName)),
 	}
+	// role admins can assign the role to anyone, so they carry its risk as well
+	adminAssignmentOptions = append(adminAssignmentOptions, riskOptions...)
 
 	rv = append(
 		rv,
@@ -163,19 +183,36 @@ func (r *roleResourceType) Grants(ctx context.Context, resource *v2.Resource, pt
 			return nil, "", nil, fmt.Errorf("onelogin-connector: failed to list users under role %s: %w", resource.Id.Resource, err)
 		}
 
+		expirations, err := r.provisioning.roleExpiries(ctx, resource.Id.Resource)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("onelogin-connector: failed to load grant expiries for role %s: %w", resource.Id.Resource, err)
+		}
+
+		mappingIds, err := r.mappings.roleMappings(ctx, resource.Id.Resource)
+		if err != nil {
+			return nil, "", nil, err
+		}
+
 		// for each user, create a grant
 		for _, user := range roleUsers {
+			userCopy := user
 			userResource := &v2.ResourceId{
 				ResourceType: resourceTypeUser.Id,
 				Resource:     strconv.Itoa(user.Id),
 			}
 
+			metadata := []map[string]interface{}{roleGrantSource(&userCopy, mappingIds)}
+			if expiration, ok := expirations[expiryKey(roleMembership, userResource.Resource)]; ok {
+				metadata = append(metadata, expiryMetadata(expiration))
+			}
+
 			rv = append(
 				rv,
 				grant.NewGrant(
 					resource,
 					roleMembership,
 					userResource,
+					grantMetadataOption(metadata...),
 				),
 			)
 		}
@@ -200,6 +237,11 @@ func (r *roleResourceType) Grants(ctx context.Context, resource *v2.Resource, pt
 			return nil, "", nil, fmt.Errorf("onelogin-connector: failed to list users under role %s: %w", resource.Id.Resource, err)
 		}
 
+		expirations, err := r.provisioning.roleExpiries(ctx, resource.Id.Resource)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("onelogin-connector: failed to load grant expiries for role %s: %w", resource.Id.Resource, err)
+		}
+
 		// for each user, create a grant
 		for _, user := range roleAdmins {
 			userResource := &v2.ResourceId{
@@ -207,12 +249,19 @@ func (r *roleResourceType) Grants(ctx context.Context, resource *v2.Resource, pt
 				Resource:     strconv.Itoa(user.Id),
 			}
 
+			// mappings only assign role membership, so admins are always assigned manually
+			metadata := []map[string]interface{}{{grantSource: grantSourceManual}}
+			if expiration, ok := expirations[expiryKey(roleAdmin, userResource.Resource)]; ok {
+				metadata = append(metadata, expiryMetadata(expiration))
+			}
+
 			rv = append(
 				rv,
 				grant.NewGrant(
 					resource,
 					roleAdmin,
 					userResource,
+					grantMetadataOption(metadata...),
 				),
 			)
 		}
@@ -237,10 +286,11 @@ func (r *roleResourceType) Grants(ctx context.Context, resource *v2.Resource, pt
 			return nil, "", nil, fmt.Errorf("onelogin-connector: failed to list apps under role %s: %w", resource.Id.Resource, err)
 		}
 
-		// for each app, create a grant
+		// for each app, grant its membership to the role; the SDK version in use cannot expand grants,
+		// so the role members are not reported as app members through it
 		for _, app := range roleApps {
 			appCopy := app
-			ur, err := appResource(&appCopy)
+			ur, err := appResource(&appCopy, nil)
 			if err != nil {
 				return nil, "", nil, err
 			}
@@ -248,9 +298,9 @@ func (r *roleResourceType) Grants(ctx context.Context, resource *v2.Resource, pt
 			rv = append(
 				rv,
 				grant.NewGrant(
-					resource,
-					roleAdmin,
-					ur.Id,
+					ur,
+					roleMembership,
+					resource.Id,
 				),
 			)
 		}
@@ -275,6 +325,14 @@ func (r *roleResourceType) Grants(ctx context.Context, resource *v2.Resource, pt
 }
 
 func (r *roleResourceType) Grant(ctx context.Context, principal *v2.Resource, entitlement *v2.Entitlement) (annotations.Annotations, error) {
+	recorder := &onelogin.ResponseRecorder{}
+	annos, err := r.grant(onelogin.WithResponseRecorder(ctx, recorder), principal, entitlement)
+	r.provisioning.record(ctx, "grant", principal.Id, entitlement.Id, entitlement.Resource.Id, recorder, annos, err)
+
+	return annos, err
+}
+
+func (r *roleResourceType) grant(ctx context.Context, principal *v2.Resource, entitlement *v2.Entitlement) (annotations.Annotations, error) {
 	l := ctxzap.Extract(ctx)
 
 	if principal.Id.ResourceType != resourceTypeUser.Id {
@@ -286,15 +344,143 @@ func (r *roleResourceType) Grant(ctx context.Context, principal *v2.Resource, en
 		return nil, fmt.Errorf("onelogin-connector: only users can be granted role membership")
 	}
 
-	err := r.client.GrantRole(ctx, entitlement.Resource.Id.Resource, principal.Id.Resource, entitlement.Slug)
+	roleId := entitlement.Resource.Id.Resource
+	userId := principal.Id.Resource
+
+	expiration, err := r.provisioning.requestedExpiry(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("onelogin-connector: invalid expiry for %s role grant: %w", entitlement.Slug, err)
+	}
+
+	err = r.provisioning.checkPolicy(ctx, r.client, entitlement)
+	if err != nil {
+		return nil, fmt.Errorf("onelogin-connector: refusing to grant %s role: %w", entitlement.Slug, err)
+	}
+
+	// retried grants must not fail because the membership is already in place
+	hasRole, err := r.userHasRole(ctx, roleId, userId, entitlement.Slug)
+	if err != nil {
+		return nil, fmt.Errorf("onelogin-connector: failed to check %s role membership: %w", entitlement.Slug, err)
+	}
+
+	if hasRole {
+		l.Info(
+			"onelogin-connector: user already has role membership",
+			zap.String("role_id", roleId),
+			zap.String("user_id", userId),
+			zap.String("entitlement", entitlement.Slug),
+		)
+
+		// the membership was not made by this grant, e.g. it predates it or comes from a mapping,
+		// so expiring it could revoke access the connector never granted
+		metadata := map[string]interface{}{grantAlreadyExists: true}
+		if !expiration.IsZero() {
+			metadata[expiryNotApplied] = true
+		}
+
+		return provisioningAnnotations(metadata)
+	}
+
+	// a dry run makes no grant, so it must not use up the admin grant cap
+	if r.provisioning.policy.DryRun {
+		l.Info(
+			"onelogin-connector: dry run, would grant role membership",
+			zap.String("role_id", roleId),
+			zap.String("user_id", userId),
+			zap.String("entitlement", entitlement.Slug),
+		)
+		return provisioningAnnotations(dryRunMetadata("grant"))
+	}
+
+	if entitlement.Slug == roleAdmin {
+		err = r.provisioning.reserveAdminGrant()
+		if err != nil {
+			return nil, fmt.Errorf("onelogin-connector: refusing to grant %s role: %w", entitlement.Slug, err)
+		}
+	}
+
+	started := time.Now()
+	err = r.provisioning.grantRole(ctx, r.client, roleId, userId, entitlement.Slug)
 	if err != nil {
+		// a grant that was not made must not use up the admin grant cap
+		if entitlement.Slug == roleAdmin {
+			r.provisioning.releaseAdminGrant()
+		}
 		return nil, fmt.Errorf("onelogin-connector: failed to grant %s role: %w", entitlement.Slug, err)
 	}
 
-	return nil, nil
+	metadata := map[string]interface{}{}
+	if r.provisioning.verifyTimeout != 0 {
+		confirmed, err := r.waitForRoleState(ctx, roleId, userId, entitlement.Slug, true)
+		if err != nil {
+			return nil, fmt.Errorf("onelogin-connector: failed to verify %s role grant: %w", entitlement.Slug, err)
+		}
+		metadata = verificationMetadata(started, confirmed)
+	}
+
+	err = r.recordExpiry(ctx, entitlement, userId, expiration, metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(metadata) == 0 {
+		return nil, nil
+	}
+
+	return provisioningAnnotations(metadata)
+}
+
+// recordExpiry stores when the grant expires, if it should, and adds the expiry to the metadata.
+func (r *roleResourceType) recordExpiry(ctx context.Context, entitlement *v2.Entitlement, userId string, expiration time.Time, metadata map[string]interface{}) error {
+	if expiration.IsZero() {
+		return nil
+	}
+
+	err := r.provisioning.expiries.Put(ctx, &expiry.Grant{
+		RoleId:      entitlement.Resource.Id.Resource,
+		RoleName:    entitlement.Resource.DisplayName,
+		UserId:      userId,
+		Entitlement: entitlement.Slug,
+		ExpiresAt:   expiration,
+	})
+	if err != nil {
+		return fmt.Errorf("onelogin-connector: failed to record expiry of %s role grant: %w", entitlement.Slug, err)
+	}
+
+	for k, v := range expiryMetadata(expiration) {
+		metadata[k] = v
+	}
+
+	return nil
+}
+
+// forgetExpiry drops the expiry of a grant that no longer exists.
+func (r *roleResourceType) forgetExpiry(ctx context.Context, roleId, userId, entitlement string) {
+	if r.provisioning.expiries == nil {
+		return
+	}
+
+	err := r.provisioning.expiries.Delete(ctx, roleId, userId, entitlement)
+	if err != nil {
+		ctxzap.Extract(ctx).Error(
+			"baton-onelogin: failed to forget expiry of revoked grant",
+			zap.String("role_id", roleId),
+			zap.String("user_id", userId),
+			zap.String("entitlement", entitlement),
+			zap.Error(err),
+		)
+	}
 }
 
 func (r *roleResourceType) Revoke(ctx context.Context, grant *v2.Grant) (annotations.Annotations, error) {
+	recorder := &onelogin.ResponseRecorder{}
+	annos, err := r.revoke(onelogin.WithResponseRecorder(ctx, recorder), grant)
+	r.provisioning.record(ctx, "revoke", grant.Principal.Id, grant.Entitlement.Id, grant.Entitlement.Resource.Id, recorder, annos, err)
+
+	return annos, err
+}
+
+func (r *roleResourceType) revoke(ctx context.Context, grant *v2.Grant) (annotations.Annotations, error) {
 	l := ctxzap.Extract(ctx)
 
 	entitlement := grant.Entitlement
@@ -309,17 +495,279 @@ func (r *roleResourceType) Revoke(ctx context.Context, grant *v2.Grant) (annotat
 		return nil, fmt.Errorf("baton-onelogin: only users can have role membership revoked")
 	}
 
-	err := r.client.RevokeRole(ctx, entitlement.Resource.Id.Resource, principal.Id.Resource, entitlement.Slug)
+	roleId := entitlement.Resource.Id.Resource
+	userId := principal.Id.Resource
+
+	// revoking a membership that is already gone is not an error, neither is revoking from a deleted role
+	hasRole, err := r.userHasRole(ctx, roleId, userId, entitlement.Slug)
+	if err != nil && !onelogin.IsNotFound(err) {
+		return nil, fmt.Errorf("baton-onelogin: failed to check %s role membership: %w", entitlement.Slug, err)
+	}
+
+	if !hasRole {
+		l.Info(
+			"baton-onelogin: user does not have role membership",
+			zap.String("role_id", roleId),
+			zap.String("user_id", userId),
+			zap.String("entitlement", entitlement.Slug),
+		)
+		r.forgetExpiry(ctx, roleId, userId, entitlement.Slug)
+		return provisioningAnnotations(map[string]interface{}{grantAlreadyRevoked: true})
+	}
+
+	err = r.provisioning.checkPolicy(ctx, r.client, entitlement)
+	if err != nil {
+		return nil, fmt.Errorf("baton-onelogin: refusing to revoke %s role: %w", entitlement.Slug, err)
+	}
+
+	if r.provisioning.policy.DryRun {
+		l.Info(
+			"baton-onelogin: dry run, would revoke role membership",
+			zap.String("role_id", roleId),
+			zap.String("user_id", userId),
+			zap.String("entitlement", entitlement.Slug),
+		)
+		return provisioningAnnotations(dryRunMetadata("revoke"))
+	}
+
+	started := time.Now()
+	err = r.provisioning.revokeRole(ctx, r.client, roleId, userId, entitlement.Slug)
 	if err != nil {
 		return nil, fmt.Errorf("baton-onelogin: failed to revoke %s role: %w", entitlement.Slug, err)
 	}
+	r.forgetExpiry(ctx, roleId, userId, entitlement.Slug)
+
+	if r.provisioning.verifyTimeout == 0 {
+		return nil, nil
+	}
+
+	confirmed, err := r.waitForRoleState(ctx, roleId, userId, entitlement.Slug, false)
+	if err != nil {
+		return nil, fmt.Errorf("baton-onelogin: failed to verify %s role revoke: %w", entitlement.Slug, err)
+	}
+
+	return provisioningAnnotations(verificationMetadata(started, confirmed))
+}
+
+// userHasRole looks the user up in the members or admins of a role, depending on the entitlement.
+// Members are checked against the roles of the user. OneLogin has no per-user lookup of role admins,
+// so admins, which are few, are paged through.
+func (r *roleResourceType) userHasRole(ctx context.Context, roleId, userId, entitlement string) (bool, error) {
+	id, err := strconv.Atoi(userId)
+	if err != nil {
+		return false, fmt.Errorf("invalid user id %s: %w", userId, err)
+	}
+
+	if entitlement != roleAdmin {
+		roleIds, err := r.client.GetUserRoleIds(ctx, userId)
+		if err != nil {
+			return false, err
+		}
+
+		for _, rid := range roleIds {
+			if strconv.Itoa(rid) == roleId {
+				return true, nil
+			}
+		}
+
+		return false, nil
+	}
+
+	cursor := ""
+	for {
+		roleAdmins, nextCursor, err := r.client.GetRoleAdmins(ctx, roleId, onelogin.PaginationVars{
+			Limit:  ResourcesPageSize,
+			Cursor: cursor,
+		})
+		if err != nil {
+			return false, err
+		}
+
+		for _, user := range roleAdmins {
+			if user.Id == id {
+				return true, nil
+			}
+		}
+
+		if nextCursor == "" {
+			return false, nil
+		}
+		cursor = nextCursor
+	}
+}
+
+// Create creates a new OneLogin role, optionally with its initial apps, users and admins.
+func (r *roleResourceType) Create(ctx context.Context, body *onelogin.RoleBody) (*v2.Resource, error) {
+	recorder := &onelogin.ResponseRecorder{}
+	resource, annos, err := r.create(onelogin.WithResponseRecorder(ctx, recorder), body)
+
+	var resourceId *v2.ResourceId
+	if resource != nil {
+		resourceId = resource.Id
+	}
+	r.provisioning.record(ctx, "role_create", nil, "", resourceId, recorder, annos, err)
+
+	return resource, err
+}
+
+func (r *roleResourceType) create(ctx context.Context, body *onelogin.RoleBody) (*v2.Resource, annotations.Annotations, error) {
+	if body.Name == "" {
+		return nil, nil, fmt.Errorf("onelogin-connector: role name must be provided")
+	}
+
+	err := r.provisioning.checkRolePolicy(ctx, r.client, "", body.Name)
+	if err != nil {
+		return nil, nil, fmt.Errorf("onelogin-connector: refusing to create role %s: %w", body.Name, err)
+	}
+
+	// a dry run returns the role as it would be created, without an ID
+	var annos annotations.Annotations
+	roleId := 0
+	if r.provisioning.policy.DryRun {
+		ctxzap.Extract(ctx).Info("onelogin-connector: dry run, would create role", zap.String("name", body.Name))
+
+		annos, err = provisioningAnnotations(dryRunMetadata("role_create"))
+		if err != nil {
+			return nil, nil, err
+		}
+	} else {
+		roleId, err = r.client.CreateRole(ctx, body)
+		if err != nil {
+			return nil, nil, fmt.Errorf("onelogin-connector: failed to create role %s: %w", body.Name, err)
+		}
+	}
+
+	resource, err := roleResource(&onelogin.Role{
+		BaseResource: onelogin.BaseResource{Id: roleId},
+		Name:         body.Name,
+		Admins:       body.Admins,
+		Users:        body.Users,
+		Apps:         body.Apps,
+	}, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	if roleId != 0 {
+		addExternalLink(r.client, resource)
+	}
+
+	return resource, annos, nil
+}
+
+// Rename changes the name of an existing OneLogin role.
+func (r *roleResourceType) Rename(ctx context.Context, roleId, name string) (*v2.Resource, error) {
+	recorder := &onelogin.ResponseRecorder{}
+	resource, annos, err := r.rename(onelogin.WithResponseRecorder(ctx, recorder), roleId, name)
+	r.provisioning.record(ctx, "role_rename", nil, "", roleResourceId(roleId), recorder, annos, err)
+
+	return resource, err
+}
+
+func (r *roleResourceType) rename(ctx context.Context, roleId, name string) (*v2.Resource, annotations.Annotations, error) {
+	if name == "" {
+		return nil, nil, fmt.Errorf("onelogin-connector: role name must be provided")
+	}
+
+	id, err := strconv.Atoi(roleId)
+	if err != nil {
+		return nil, nil, fmt.Errorf("onelogin-connector: invalid role id %s: %w", roleId, err)
+	}
+
+	// both the current and the new name are checked, so a rename cannot take a role out of protection
+	err = r.provisioning.checkRolePolicy(ctx, r.client, roleId, name)
+	if err != nil {
+		return nil, nil, fmt.Errorf("onelogin-connector: refusing to rename role %s: %w", roleId, err)
+	}
+
+	var annos annotations.Annotations
+	if r.provisioning.policy.DryRun {
+		ctxzap.Extract(ctx).Info(
+			"onelogin-connector: dry run, would rename role",
+			zap.String("role_id", roleId),
+			zap.String("name", name),
+		)
+
+		annos, err = provisioningAnnotations(dryRunMetadata("role_rename"))
+		if err != nil {
+			return nil, nil, err
+		}
+	} else {
+		err = r.client.UpdateRole(ctx, roleId, &onelogin.RoleBody{Name: name})
+		if err != nil {
+			return nil, nil, fmt.Errorf("onelogin-connector: failed to rename role %s: %w", roleId, err)
+		}
+	}
+
+	resource, err := roleResource(&onelogin.Role{
+		BaseResource: onelogin.BaseResource{Id: id},
+		Name:         name,
+	}, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	addExternalLink(r.client, resource)
+
+	return resource, annos, nil
+}
+
+// Delete removes a OneLogin role. Roles that still have apps attached are only
+// removed when force is set, since deleting them silently drops app access.
+func (r *roleResourceType) Delete(ctx context.Context, roleId string, force bool) error {
+	recorder := &onelogin.ResponseRecorder{}
+	annos, err := r.delete(onelogin.WithResponseRecorder(ctx, recorder), roleId, force)
+	r.provisioning.record(ctx, "role_delete", nil, "", roleResourceId(roleId), recorder, annos, err)
+
+	return err
+}
+
+func (r *roleResourceType) delete(ctx context.Context, roleId string, force bool) (annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	// force only skips the check for attached apps, never the provisioning policy
+	err := r.provisioning.checkRolePolicy(ctx, r.client, roleId, "")
+	if err != nil {
+		return nil, fmt.Errorf("onelogin-connector: refusing to delete role %s: %w", roleId, err)
+	}
+
+	if !force {
+		roleApps, _, err := r.client.GetRoleApps(ctx, roleId, onelogin.PaginationVars{Limit: 1})
+		if err != nil {
+			return nil, fmt.Errorf("onelogin-connector: failed to list apps under role %s: %w", roleId, err)
+		}
+
+		if len(roleApps) != 0 {
+			return nil, fmt.Errorf("onelogin-connector: role %s still has apps attached, use force to delete it", roleId)
+		}
+	}
+
+	if r.provisioning.policy.DryRun {
+		l.Info("onelogin-connector: dry run, would delete role", zap.String("role_id", roleId), zap.Bool("force", force))
+		return provisioningAnnotations(dryRunMetadata("role_delete"))
+	}
+
+	err = r.client.DeleteRole(ctx, roleId)
+	if err != nil {
+		return nil, fmt.Errorf("onelogin-connector: failed to delete role %s: %w", roleId, err)
+	}
+
+	l.Info("onelogin-connector: role deleted", zap.String("role_id", roleId), zap.Bool("force", force))
 
 	return nil, nil
 }
 
-func roleBuilder(client *onelogin.Client) *roleResourceType {
+func roleResourceId(roleId string) *v2.ResourceId {
+	return &v2.ResourceId{
+		ResourceType: resourceTypeRole.Id,
+		Resource:     roleId,
+	}
+}
+
+func roleBuilder(client *onelogin.Client, provisioning *provisioning, mappings *mappingIndex, privileges *rolePrivilegeIndex) *roleResourceType {
 	return &roleResourceType{
 		resourceType: resourceTypeRole,
 		client:       client,
+		provisioning: provisioning,
+		mappings:     mappings,
+		privileges:   privileges,
 	}
 }
@@ -0,0 +1,99 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/conductorone/baton-onelogin/pkg/onelogin"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+)
+
+type selfRegistrationProfileResourceType struct {
+	resourceType *v2.ResourceType
+	client       *onelogin.Client
+}
+
+func (s *selfRegistrationProfileResourceType) ResourceType(_ context.Context) *v2.ResourceType {
+	return s.resourceType
+}
+
+// Create a new connector resource for an OneLogin self-registration profile. The default role and group
+// the profile hands out at sign-up are part of the profile; they are not access held by the profile itself.
+func selfRegistrationProfileResource(profile *onelogin.SelfRegistrationProfile) (*v2.Resource, error) {
+	resourceProfile := map[string]interface{}{
+		"self_registration_profile_id":   profile.Id,
+		"self_registration_profile_name": profile.Name,
+		"url":                            profile.URL,
+		"enabled":                        profile.Enabled,
+		"moderated":                      profile.Moderated,
+		"allowed_domains":                stringsToInterfaces(splitDomains(profile.DomainWhitelist)),
+		"blocked_domains":                stringsToInterfaces(splitDomains(profile.DomainBlacklist)),
+	}
+	if profile.DefaultRoleId != nil {
+		resourceProfile["default_role_id"] = strconv.Itoa(*profile.DefaultRoleId)
+	}
+	if profile.DefaultGroupId != nil {
+		resourceProfile["default_group_id"] = strconv.Itoa(*profile.DefaultGroupId)
+	}
+
+	resource, err := inventoryResource(
+		profile.Name,
+		resourceTypeSelfRegistrationProfile,
+		profile.Id,
+		resourceProfile,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return resource, nil
+}
+
+// splitDomains splits a comma or whitespace separated domain list.
+func splitDomains(domains string) []string {
+	return strings.FieldsFunc(domains, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\n'
+	})
+}
+
+func (s *selfRegistrationProfileResourceType) List(ctx context.Context, _ *v2.ResourceId, _ *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	profiles, err := s.client.GetSelfRegistrationProfiles(ctx)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("onelogin-connector: failed to list self-registration profiles: %w", err)
+	}
+
+	var rv []*v2.Resource
+	for _, profile := range profiles {
+		profileCopy := profile
+		pr, err := selfRegistrationProfileResource(&profileCopy)
+
+		if err != nil {
+			return nil, "", nil, err
+		}
+		addExternalLink(s.client, pr)
+
+		rv = append(rv, pr)
+	}
+
+	return rv, "", nil, nil
+}
+
+func (s *selfRegistrationProfileResourceType) Entitlements(_ context.Context, _ *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	return nil, "", nil, nil
+}
+
+func (s *selfRegistrationProfileResourceType) Grants(_ context.Context, _ *v2.Resource, _ *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	return nil, "", nil, nil
+}
+
+func selfRegistrationProfileBuilder(client *onelogin.Client) *selfRegistrationProfileResourceType {
+	return &selfRegistrationProfileResourceType{
+		resourceType: resourceTypeSelfRegistrationProfile,
+		client:       client,
+	}
+}
@@ -0,0 +1,96 @@
+package connector
+
+import (
+	"context"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/types"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+// Assets are streamed in chunks well below the gRPC message size limit.
+const assetChunkSize = 32 * 1024
+
+// fallbackAssetContentType is reported for assets that could not be downloaded.
+const fallbackAssetContentType = "application/octet-stream"
+
+type requestAnnotationsKey struct{}
+
+// connectorServer passes the annotations of Grant requests on to the provisioners,
+// which the SDK connector builder does not do on its own, and serves the app icons.
+type connectorServer struct {
+	types.ConnectorServer
+	assets *assetCache
+}
+
+// WrapConnectorServer wraps the connector server built by the SDK with OneLogin specific request handling.
+func WrapConnectorServer(c types.ConnectorServer, o *OneLogin) types.ConnectorServer {
+	return &connectorServer{
+		ConnectorServer: c,
+		assets:          o.assets,
+	}
+}
+
+func (s *connectorServer) Grant(ctx context.Context, request *v2.GrantManagerServiceGrantRequest) (*v2.GrantManagerServiceGrantResponse, error) {
+	ctx = context.WithValue(ctx, requestAnnotationsKey{}, annotations.Annotations(request.Annotations))
+	return s.ConnectorServer.Grant(ctx, request)
+}
+
+// GetAsset streams an app icon. The sync fails on any asset error, so an icon that cannot be
+// downloaded is logged and served empty instead.
+func (s *connectorServer) GetAsset(request *v2.AssetServiceGetAssetRequest, server v2.AssetService_GetAssetServer) error {
+	ctx := server.Context()
+	l := ctxzap.Extract(ctx)
+
+	assetId := request.GetAsset().GetId()
+	contentType := fallbackAssetContentType
+	var data []byte
+
+	a, err := s.assets.get(ctx, assetId)
+	if err != nil {
+		l.Warn("onelogin-connector: failed to get asset", zap.String("asset_id", assetId), zap.Error(err))
+	} else {
+		contentType = a.contentType
+		data = a.data
+	}
+
+	err = server.Send(&v2.AssetServiceGetAssetResponse{
+		Msg: &v2.AssetServiceGetAssetResponse_Metadata_{
+			Metadata: &v2.AssetServiceGetAssetResponse_Metadata{
+				ContentType: contentType,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	for len(data) > 0 {
+		chunk := data
+		if len(chunk) > assetChunkSize {
+			chunk = chunk[:assetChunkSize]
+		}
+		data = data[len(chunk):]
+
+		err = server.Send(&v2.AssetServiceGetAssetResponse{
+			Msg: &v2.AssetServiceGetAssetResponse_Data_{
+				Data: &v2.AssetServiceGetAssetResponse_Data{
+					Data: chunk,
+				},
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// requestAnnotations returns the annotations of the request being handled, if any.
+func requestAnnotations(ctx context.Context) annotations.Annotations {
+	annos, _ := ctx.Value(requestAnnotationsKey{}).(annotations.Annotations)
+	return annos
+}
@@ -0,0 +1,122 @@
+package connector
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	"github.com/conductorone/baton-onelogin/pkg/onelogin"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+)
+
+type smartHookResourceType struct {
+	resourceType *v2.ResourceType
+	client       *onelogin.Client
+}
+
+func (s *smartHookResourceType) ResourceType(_ context.Context) *v2.ResourceType {
+	return s.resourceType
+}
+
+// Create a new connector resource for an OneLogin Smart Hook. The function source is only kept
+// as a hash and environment variables only by name, so no code or secret ends up in a sync.
+func smartHookResource(hook *onelogin.Hook) (*v2.Resource, error) {
+	envVars := make([]string, 0, len(hook.EnvVars))
+	for _, envVar := range hook.EnvVars {
+		envVars = append(envVars, envVar.Name)
+	}
+	sort.Strings(envVars)
+
+	packages := make(map[string]interface{}, len(hook.Packages))
+	for name, version := range hook.Packages {
+		packages[name] = version
+	}
+
+	profile := map[string]interface{}{
+		"hook_id":       hook.Id,
+		"hook_type":     hook.Type,
+		"hook_status":   hook.Status,
+		"hook_disabled": hook.Disabled,
+		"runtime":       hook.Runtime,
+		"timeout":       hook.Timeout,
+		"retries":       hook.Retries,
+		"env_var_names": stringsToInterfaces(envVars),
+		"packages":      packages,
+		"created_at":    hook.CreatedAt,
+		"updated_at":    hook.UpdatedAt,
+	}
+	// the function source is left out of the hash when it could not be read
+	if hook.Function != "" {
+		sum := sha256.Sum256([]byte(hook.Function))
+		profile["function_sha256"] = hex.EncodeToString(sum[:])
+	}
+
+	resource, err := inventoryResource(
+		fmt.Sprintf("%s hook %s", hook.Type, hook.Id),
+		resourceTypeSmartHook,
+		hook.Id,
+		profile,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return resource, nil
+}
+
+func (s *smartHookResourceType) List(ctx context.Context, _ *v2.ResourceId, _ *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	hooks, err := s.client.GetHooks(ctx)
+	if err != nil {
+		if skipUnavailable(ctx, err, "smart hooks") {
+			return nil, "", nil, nil
+		}
+		return nil, "", nil, fmt.Errorf("onelogin-connector: failed to list smart hooks: %w", err)
+	}
+
+	var rv []*v2.Resource
+	for _, hook := range hooks {
+		hookCopy := hook
+
+		// the function source is not always part of the listing
+		if hookCopy.Function == "" {
+			detail, err := s.client.GetHook(ctx, hook.Id)
+			switch {
+			case err == nil:
+				hookCopy.Function = detail.Function
+			case !skipUnavailable(ctx, err, "smart hook function"):
+				return nil, "", nil, fmt.Errorf("onelogin-connector: failed to get smart hook %s: %w", hook.Id, err)
+			}
+		}
+
+		hr, err := smartHookResource(&hookCopy)
+
+		if err != nil {
+			return nil, "", nil, err
+		}
+
+		rv = append(rv, hr)
+	}
+
+	return rv, "", nil, nil
+}
+
+// Smart Hooks run code rather than grant access, so they have no entitlements or grants of their own.
+func (s *smartHookResourceType) Entitlements(_ context.Context, _ *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	return nil, "", nil, nil
+}
+
+func (s *smartHookResourceType) Grants(_ context.Context, _ *v2.Resource, _ *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	return nil, "", nil, nil
+}
+
+func smartHookBuilder(client *onelogin.Client) *smartHookResourceType {
+	return &smartHookResourceType{
+		resourceType: resourceTypeSmartHook,
+		client:       client,
+	}
+}
@@ -0,0 +1,168 @@
+package connector
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/conductorone/baton-onelogin/pkg/onelogin"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	ent "github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+)
+
+const (
+	federatedUser = "federated_user"
+
+	// user attribute holding the trusted IdP a user authenticates through
+	trustedIdpUserAttribute = "trusted_idp_id"
+)
+
+type trustedIdpResourceType struct {
+	resourceType   *v2.ResourceType
+	client         *onelogin.Client
+	userAttributes *userAttributeIndex
+}
+
+func (t *trustedIdpResourceType) ResourceType(_ context.Context) *v2.ResourceType {
+	return t.resourceType
+}
+
+// Create a new connector resource for an OneLogin trusted IdP.
+func trustedIdpResource(idp *onelogin.TrustedIdp) (*v2.Resource, error) {
+	profile := map[string]interface{}{
+		"trusted_idp_id":      idp.Id,
+		"trusted_idp_name":    idp.Name,
+		"trusted_idp_enabled": idp.Enabled,
+		"issuer":              idp.Issuer,
+		"sso_endpoint":        idp.SSOEndpoint,
+	}
+	if notAfter, ok := certificateExpiry(idp.Certificate); ok {
+		profile["certificate_expires_at"] = notAfter.UTC().Format(time.RFC3339)
+	}
+
+	resource, err := inventoryResource(
+		idp.Name,
+		resourceTypeTrustedIdp,
+		idp.Id,
+		profile,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return resource, nil
+}
+
+// certificateExpiry returns the expiry of a certificate given either as PEM or as bare base64 DER.
+func certificateExpiry(certificate string) (time.Time, bool) {
+	certificate = strings.TrimSpace(certificate)
+	if certificate == "" {
+		return time.Time{}, false
+	}
+
+	var der []byte
+	if block, _ := pem.Decode([]byte(certificate)); block != nil {
+		der = block.Bytes
+	} else {
+		decoded, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(certificate), ""))
+		if err != nil {
+			return time.Time{}, false
+		}
+		der = decoded
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return cert.NotAfter, true
+}
+
+func (t *trustedIdpResourceType) List(ctx context.Context, _ *v2.ResourceId, _ *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	idps, err := t.client.GetTrustedIdps(ctx)
+	if err != nil {
+		if skipUnavailable(ctx, err, "trusted identity providers") {
+			return nil, "", nil, nil
+		}
+		return nil, "", nil, fmt.Errorf("onelogin-connector: failed to list trusted idps: %w", err)
+	}
+
+	var rv []*v2.Resource
+	for _, idp := range idps {
+		idpCopy := idp
+		ir, err := trustedIdpResource(&idpCopy)
+
+		if err != nil {
+			return nil, "", nil, err
+		}
+		addExternalLink(t.client, ir)
+
+		rv = append(rv, ir)
+	}
+
+	return rv, "", nil, nil
+}
+
+func (t *trustedIdpResourceType) Entitlements(_ context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	var rv []*v2.Entitlement
+	federatedOptions := []ent.EntitlementOption{
+		ent.WithGrantableTo(resourceTypeUser),
+		ent.WithDisplayName(fmt.Sprintf("%s Trusted IdP %s", resource.DisplayName, federatedUser)),
+		ent.WithDescription(fmt.Sprintf("Authentication through %s trusted IdP in OneLogin", resource.DisplayName)),
+	}
+
+	rv = append(
+		rv,
+		ent.NewAssignmentEntitlement(
+			resource,
+			federatedUser,
+			federatedOptions...,
+		),
+	)
+
+	return rv, "", nil, nil
+}
+
+func (t *trustedIdpResourceType) Grants(ctx context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	userIds, err := t.userAttributes.matching(ctx, trustedIdpUserAttribute, resource.Id.Resource)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	var rv []*v2.Grant
+	for _, userId := range userIds {
+		userResource := &v2.ResourceId{
+			ResourceType: resourceTypeUser.Id,
+			Resource:     strconv.Itoa(userId),
+		}
+
+		rv = append(
+			rv,
+			grant.NewGrant(
+				resource,
+				federatedUser,
+				userResource,
+			),
+		)
+	}
+
+	return rv, "", nil, nil
+}
+
+func trustedIdpBuilder(client *onelogin.Client, userAttributes *userAttributeIndex) *trustedIdpResourceType {
+	return &trustedIdpResourceType{
+		resourceType:   resourceTypeTrustedIdp,
+		client:         client,
+		userAttributes: userAttributes,
+	}
+}
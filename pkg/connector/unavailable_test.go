@@ -0,0 +1,170 @@
+package connector
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/conductorone/baton-onelogin/pkg/onelogin"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+)
+
+// TestSyncSkipsUnavailableData checks that data the API credentials cannot read is left out of the
+// sync, while any other failure still fails it.
+func TestSyncSkipsUnavailableData(t *testing.T) {
+	syncers := []struct {
+		name  string
+		route string
+		list  func(ctx context.Context, client *onelogin.Client) ([]*v2.Resource, error)
+	}{
+		{
+			name:  "privileges",
+			route: "GET /api/2/privileges",
+			list: func(ctx context.Context, client *onelogin.Client) ([]*v2.Resource, error) {
+				rv, _, _, err := privilegeBuilder(client, &provisioning{}).List(ctx, nil, &pagination.Token{})
+				return rv, err
+			},
+		},
+		{
+			name:  "api authorizations",
+			route: "GET /api/2/api_authorizations",
+			list: func(ctx context.Context, client *onelogin.Client) ([]*v2.Resource, error) {
+				rv, _, _, err := apiAuthorizationBuilder(client, &provisioning{}).List(ctx, nil, &pagination.Token{})
+				return rv, err
+			},
+		},
+		{
+			name:  "smart hooks",
+			route: "GET /api/2/hooks",
+			list: func(ctx context.Context, client *onelogin.Client) ([]*v2.Resource, error) {
+				rv, _, _, err := smartHookBuilder(client).List(ctx, nil, &pagination.Token{})
+				return rv, err
+			},
+		},
+		{
+			name:  "trusted identity providers",
+			route: "GET /api/2/trusted_idps",
+			list: func(ctx context.Context, client *onelogin.Client) ([]*v2.Resource, error) {
+				rv, _, _, err := trustedIdpBuilder(client, nil).List(ctx, nil, &pagination.Token{})
+				return rv, err
+			},
+		},
+		{
+			name:  "brands",
+			route: "GET /api/2/branding/brands",
+			list: func(ctx context.Context, client *onelogin.Client) ([]*v2.Resource, error) {
+				rv, _, _, err := brandBuilder(client).List(ctx, nil, &pagination.Token{})
+				return rv, err
+			},
+		},
+		{
+			name:  "policies",
+			route: "GET /api/2/policies",
+			list: func(ctx context.Context, client *onelogin.Client) ([]*v2.Resource, error) {
+				rv, _, _, err := policyBuilder(client, nil).List(ctx, nil, &pagination.Token{})
+				return rv, err
+			},
+		},
+		{
+			name:  "mappings",
+			route: "GET /api/2/mappings",
+			list: func(ctx context.Context, client *onelogin.Client) ([]*v2.Resource, error) {
+				rv, _, _, err := mappingBuilder(newMappingIndex(client)).List(ctx, nil, &pagination.Token{})
+				return rv, err
+			},
+		},
+	}
+
+	statuses := []struct {
+		status   int
+		wantSkip bool
+	}{
+		{status: http.StatusUnauthorized, wantSkip: true},
+		{status: http.StatusForbidden, wantSkip: true},
+		{status: http.StatusNotFound, wantSkip: true},
+		{status: http.StatusTooManyRequests},
+		{status: http.StatusInternalServerError},
+	}
+
+	for _, syncer := range syncers {
+		for _, tt := range statuses {
+			t.Run(syncer.name+"/"+http.StatusText(tt.status), func(t *testing.T) {
+				api := newFakeOneLogin(map[string]fakeRoute{syncer.route: statusRoute(tt.status)})
+
+				resources, err := syncer.list(context.Background(), api.client(t))
+				if tt.wantSkip {
+					if err != nil || len(resources) != 0 {
+						t.Fatalf("expected the data to be skipped, got %d resources and error %v", len(resources), err)
+					}
+					return
+				}
+
+				if err == nil {
+					t.Fatalf("expected status %d to fail the sync", tt.status)
+				}
+			})
+		}
+	}
+}
+
+func TestRoleGrantsWithoutMappings(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		wantErr bool
+	}{
+		{name: "mappings are forbidden", status: http.StatusForbidden},
+		{name: "mappings fail", status: http.StatusInternalServerError, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			api := newFakeOneLogin(map[string]fakeRoute{
+				"GET /api/2/mappings":        statusRoute(tt.status),
+				"GET /api/2/roles/10/admins": staticRoute([]onelogin.UserUnderRole{}),
+				"GET /api/2/roles/10/apps":   staticRoute([]onelogin.App{}),
+				"GET /api/2/roles/10/users": staticRoute([]onelogin.UserUnderRole{
+					{
+						BaseResource: onelogin.BaseResource{Id: 1},
+						AddedBy:      &onelogin.Assigner{Id: 5, Type: assignerTypeMapping},
+					},
+				}),
+			})
+			client := api.client(t)
+			r := roleBuilder(client, &provisioning{}, newMappingIndex(client), nil)
+			role := &v2.Resource{Id: roleResourceId("10")}
+
+			var grants []*v2.Grant
+			token := ""
+			for {
+				page, next, _, err := r.Grants(ctx, role, &pagination.Token{Token: token})
+				if err != nil {
+					if !tt.wantErr {
+						t.Fatalf("unexpected error: %v", err)
+					}
+					return
+				}
+
+				grants = append(grants, page...)
+				if next == "" {
+					break
+				}
+				token = next
+			}
+			if tt.wantErr {
+				t.Fatalf("expected status %d to fail the sync", tt.status)
+			}
+
+			if len(grants) != 1 {
+				t.Fatalf("expected 1 grant, got %d", len(grants))
+			}
+			metadata := annotationMetadata(t, annotations.Annotations(grants[0].Annotations))
+			if metadata[grantSource] != grantSourceManual {
+				t.Fatalf("expected the grant to be manual without mappings, got %v", metadata)
+			}
+		})
+	}
+}
@@ -169,6 +169,7 @@ func (u *userResourceType) List(ctx context.Context, _ *v2.ResourceId, pt *pagin
 		if err != nil {
 			return nil, "", nil, err
 		}
+		addExternalLink(u.client, res)
 		resources = append(resources, res)
 	}
 
@@ -0,0 +1,99 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/conductorone/baton-onelogin/pkg/onelogin"
+)
+
+// userAttributeIndex caches every user with all of their attributes, to resolve app parameter values.
+type userAttributeIndex struct {
+	client    *onelogin.Client
+	mutex     sync.Mutex
+	users     map[int]onelogin.UserAttributes
+	timestamp time.Time
+}
+
+func newUserAttributeIndex(client *onelogin.Client) *userAttributeIndex {
+	return &userAttributeIndex{client: client}
+}
+
+// refresh reloads the users if the TTL expired. The caller must hold the mutex.
+func (u *userAttributeIndex) refresh(ctx context.Context) error {
+	if u.users != nil && time.Since(u.timestamp) < usersCacheTTL {
+		return nil
+	}
+
+	users := make(map[int]onelogin.UserAttributes)
+	cursor := ""
+
+	for {
+		page, nextCursor, err := u.client.GetUsersAttributes(ctx, onelogin.PaginationVars{
+			Limit:  ResourcesPageSize,
+			Cursor: cursor,
+		})
+		if err != nil {
+			return fmt.Errorf("onelogin-connector: failed to load user attributes: %w", err)
+		}
+
+		for _, user := range page {
+			users[user.Id()] = user
+		}
+		if nextCursor == "" {
+			break
+		}
+		cursor = nextCursor
+	}
+
+	u.users = users
+	u.timestamp = time.Now()
+	return nil
+}
+
+// get returns the attributes of a user, or nil if the user is unknown.
+func (u *userAttributeIndex) get(ctx context.Context, userId int) (onelogin.UserAttributes, error) {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	if err := u.refresh(ctx); err != nil {
+		return nil, err
+	}
+
+	return u.users[userId], nil
+}
+
+// all returns every user by ID. The map is shared and must not be modified.
+func (u *userAttributeIndex) all(ctx context.Context) (map[int]onelogin.UserAttributes, error) {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	if err := u.refresh(ctx); err != nil {
+		return nil, err
+	}
+
+	return u.users, nil
+}
+
+// matching returns the IDs of the users whose attribute holds the given value, in ascending order.
+func (u *userAttributeIndex) matching(ctx context.Context, attribute, value string) ([]int, error) {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	if err := u.refresh(ctx); err != nil {
+		return nil, err
+	}
+
+	var rv []int
+	for id, user := range u.users {
+		if user.Value(attribute) == value {
+			rv = append(rv, id)
+		}
+	}
+	sort.Ints(rv)
+
+	return rv, nil
+}
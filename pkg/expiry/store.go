@@ -0,0 +1,181 @@
+package expiry
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	// The pure Go sqlite driver is already used by the SDK for c1z files.
+	_ "github.com/glebarez/go-sqlite"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS expiring_grants (
+	role_id TEXT NOT NULL,
+	role_name TEXT NOT NULL DEFAULT '',
+	user_id TEXT NOT NULL,
+	entitlement TEXT NOT NULL,
+	expires_at INTEGER NOT NULL,
+	created_at INTEGER NOT NULL,
+	attempts INTEGER NOT NULL DEFAULT 0,
+	last_error TEXT NOT NULL DEFAULT '',
+	PRIMARY KEY (role_id, user_id, entitlement)
+);
+CREATE INDEX IF NOT EXISTS expiring_grants_expires_at ON expiring_grants (expires_at);
+`
+
+// Grant is a role grant that has to be revoked once it expires.
+type Grant struct {
+	RoleId      string
+	RoleName    string
+	UserId      string
+	Entitlement string
+	ExpiresAt   time.Time
+	Attempts    int
+	LastError   string
+}
+
+// Store keeps expiring grants in a local SQLite file.
+type Store struct {
+	db *sql.DB
+}
+
+// Open opens the store at path, creating the file and its schema if needed.
+func Open(ctx context.Context, path string) (*Store, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+
+	// sqlite only supports a single writer
+	db.SetMaxOpenConns(1)
+
+	_, err = db.ExecContext(ctx, schema)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create expiring grants schema: %w", err)
+	}
+
+	return &Store{db: db}, nil
+}
+
+// Put records the expiry of a grant, replacing any expiry recorded before.
+func (s *Store) Put(ctx context.Context, grant *Grant) error {
+	_, err := s.db.ExecContext(
+		ctx,
+		`INSERT INTO expiring_grants (role_id, role_name, user_id, entitlement, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (role_id, user_id, entitlement) DO UPDATE SET
+			role_name = excluded.role_name,
+			expires_at = excluded.expires_at,
+			attempts = 0,
+			last_error = ''`,
+		grant.RoleId,
+		grant.RoleName,
+		grant.UserId,
+		grant.Entitlement,
+		grant.ExpiresAt.Unix(),
+		time.Now().Unix(),
+	)
+
+	return err
+}
+
+// Delete forgets a grant, e.g. once it was revoked.
+func (s *Store) Delete(ctx context.Context, roleId, userId, entitlement string) error {
+	_, err := s.db.ExecContext(
+		ctx,
+		`DELETE FROM expiring_grants WHERE role_id = ? AND user_id = ? AND entitlement = ?`,
+		roleId,
+		userId,
+		entitlement,
+	)
+
+	return err
+}
+
+// MarkFailed records a failed attempt to revoke an expired grant, so it is retried later.
+func (s *Store) MarkFailed(ctx context.Context, grant *Grant, revokeErr error) error {
+	_, err := s.db.ExecContext(
+		ctx,
+		`UPDATE expiring_grants SET attempts = attempts + 1, last_error = ?
+		WHERE role_id = ? AND user_id = ? AND entitlement = ?`,
+		revokeErr.Error(),
+		grant.RoleId,
+		grant.UserId,
+		grant.Entitlement,
+	)
+
+	return err
+}
+
+// Due returns the grants that expired at or before now.
+func (s *Store) Due(ctx context.Context, now time.Time) ([]*Grant, error) {
+	return s.query(
+		ctx,
+		`SELECT role_id, role_name, user_id, entitlement, expires_at, attempts, last_error
+		FROM expiring_grants WHERE expires_at <= ? ORDER BY expires_at`,
+		now.Unix(),
+	)
+}
+
+// ForRole returns the expiring grants of a role.
+func (s *Store) ForRole(ctx context.Context, roleId string) ([]*Grant, error) {
+	return s.query(
+		ctx,
+		`SELECT role_id, role_name, user_id, entitlement, expires_at, attempts, last_error
+		FROM expiring_grants WHERE role_id = ?`,
+		roleId,
+	)
+}
+
+// Close closes the underlying database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+func (s *Store) query(ctx context.Context, query string, args ...interface{}) ([]*Grant, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rv []*Grant
+	for rows.Next() {
+		var expiresAt int64
+		grant := &Grant{}
+
+		err := rows.Scan(&grant.RoleId, &grant.RoleName, &grant.UserId, &grant.Entitlement, &expiresAt, &grant.Attempts, &grant.LastError)
+		if err != nil {
+			return nil, err
+		}
+		grant.ExpiresAt = time.Unix(expiresAt, 0).UTC()
+
+		rv = append(rv, grant)
+	}
+
+	return rv, rows.Err()
+}
+
+// ParseExpiry parses an expiry given either as a duration relative to now or as an RFC3339 timestamp.
+func ParseExpiry(value string, now time.Time) (time.Time, error) {
+	if duration, err := time.ParseDuration(value); err == nil {
+		if duration <= 0 {
+			return time.Time{}, fmt.Errorf("expiry duration %s must be positive", value)
+		}
+		return now.Add(duration), nil
+	}
+
+	expiresAt, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expiry %s is neither a duration nor an RFC3339 timestamp", value)
+	}
+
+	if !expiresAt.After(now) {
+		return time.Time{}, fmt.Errorf("expiry %s is in the past", value)
+	}
+
+	return expiresAt, nil
+}
@@ -0,0 +1,250 @@
+package expiry
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestParseExpiry(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{name: "duration", value: "72h", want: now.Add(72 * time.Hour)},
+		{name: "compound duration", value: "1h30m", want: now.Add(90 * time.Minute)},
+		{name: "timestamp", value: "2024-04-01T00:00:00Z", want: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "timestamp with offset", value: "2024-03-01T14:00:00+01:00", want: now.Add(time.Hour)},
+		{name: "zero duration", value: "0s", wantErr: true},
+		{name: "negative duration", value: "-1h", wantErr: true},
+		{name: "past timestamp", value: "2024-02-01T00:00:00Z", wantErr: true},
+		{name: "timestamp equal to now", value: "2024-03-01T12:00:00Z", wantErr: true},
+		{name: "garbage", value: "next week", wantErr: true},
+		{name: "empty", value: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseExpiry(tt.value, now)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %s", got)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !got.Equal(tt.want) {
+				t.Fatalf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func openStore(t *testing.T) *Store {
+	t.Helper()
+
+	store, err := Open(context.Background(), filepath.Join(t.TempDir(), "expiry.db"))
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	return store
+}
+
+func TestStorePut(t *testing.T) {
+	ctx := context.Background()
+	base := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		puts   []*Grant
+		failed bool
+		want   []*Grant
+	}{
+		{
+			name: "single grant",
+			puts: []*Grant{
+				{RoleId: "10", RoleName: "Admins", UserId: "1", Entitlement: "member", ExpiresAt: base},
+			},
+			want: []*Grant{
+				{RoleId: "10", RoleName: "Admins", UserId: "1", Entitlement: "member", ExpiresAt: base},
+			},
+		},
+		{
+			name: "upsert replaces expiry and name",
+			puts: []*Grant{
+				{RoleId: "10", RoleName: "Admins", UserId: "1", Entitlement: "member", ExpiresAt: base},
+				{RoleId: "10", RoleName: "Administrators", UserId: "1", Entitlement: "member", ExpiresAt: base.Add(time.Hour)},
+			},
+			want: []*Grant{
+				{RoleId: "10", RoleName: "Administrators", UserId: "1", Entitlement: "member", ExpiresAt: base.Add(time.Hour)},
+			},
+		},
+		{
+			name: "upsert resets failed attempts",
+			puts: []*Grant{
+				{RoleId: "10", UserId: "1", Entitlement: "member", ExpiresAt: base},
+				{RoleId: "10", UserId: "1", Entitlement: "member", ExpiresAt: base.Add(time.Hour)},
+			},
+			failed: true,
+			want: []*Grant{
+				{RoleId: "10", UserId: "1", Entitlement: "member", ExpiresAt: base.Add(time.Hour)},
+			},
+		},
+		{
+			name: "entitlements are kept apart",
+			puts: []*Grant{
+				{RoleId: "10", UserId: "1", Entitlement: "member", ExpiresAt: base},
+				{RoleId: "10", UserId: "1", Entitlement: "admin", ExpiresAt: base.Add(time.Hour)},
+			},
+			want: []*Grant{
+				{RoleId: "10", UserId: "1", Entitlement: "member", ExpiresAt: base},
+				{RoleId: "10", UserId: "1", Entitlement: "admin", ExpiresAt: base.Add(time.Hour)},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := openStore(t)
+
+			for i, grant := range tt.puts {
+				err := store.Put(ctx, grant)
+				if err != nil {
+					t.Fatalf("failed to put grant: %v", err)
+				}
+
+				if tt.failed && i == 0 {
+					err = store.MarkFailed(ctx, grant, errors.New("boom"))
+					if err != nil {
+						t.Fatalf("failed to mark grant as failed: %v", err)
+					}
+				}
+			}
+
+			got, err := store.ForRole(ctx, "10")
+			if err != nil {
+				t.Fatalf("failed to list grants: %v", err)
+			}
+			sort.Slice(got, func(i, j int) bool { return got[i].ExpiresAt.Before(got[j].ExpiresAt) })
+
+			assertGrants(t, got, tt.want)
+			for _, grant := range got {
+				if grant.Attempts != 0 || grant.LastError != "" {
+					t.Fatalf("expected no failed attempts, got %d (%s)", grant.Attempts, grant.LastError)
+				}
+			}
+		})
+	}
+}
+
+func TestStoreDue(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	store := openStore(t)
+	grants := []*Grant{
+		{RoleId: "10", UserId: "1", Entitlement: "member", ExpiresAt: now.Add(-2 * time.Hour)},
+		{RoleId: "10", UserId: "2", Entitlement: "member", ExpiresAt: now},
+		{RoleId: "11", UserId: "1", Entitlement: "admin", ExpiresAt: now.Add(-time.Hour)},
+		{RoleId: "11", UserId: "2", Entitlement: "admin", ExpiresAt: now.Add(time.Hour)},
+	}
+	for _, grant := range grants {
+		err := store.Put(ctx, grant)
+		if err != nil {
+			t.Fatalf("failed to put grant: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		sweep  func(t *testing.T)
+		at     time.Time
+		want   []*Grant
+		failed map[string]int
+	}{
+		{
+			name: "before any expiry",
+			at:   now.Add(-3 * time.Hour),
+		},
+		{
+			name: "expired grants in expiry order",
+			at:   now,
+			want: []*Grant{grants[0], grants[2], grants[1]},
+		},
+		{
+			name: "failed revoke is retried",
+			sweep: func(t *testing.T) {
+				err := store.MarkFailed(ctx, grants[0], errors.New("rate limited"))
+				if err != nil {
+					t.Fatalf("failed to mark grant as failed: %v", err)
+				}
+			},
+			at:     now,
+			want:   []*Grant{grants[0], grants[2], grants[1]},
+			failed: map[string]int{"1": 1},
+		},
+		{
+			name: "revoked grants are gone",
+			sweep: func(t *testing.T) {
+				for _, grant := range []*Grant{grants[0], grants[2]} {
+					err := store.Delete(ctx, grant.RoleId, grant.UserId, grant.Entitlement)
+					if err != nil {
+						t.Fatalf("failed to delete grant: %v", err)
+					}
+				}
+			},
+			at:   now.Add(2 * time.Hour),
+			want: []*Grant{grants[1], grants[3]},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.sweep != nil {
+				tt.sweep(t)
+			}
+
+			got, err := store.Due(ctx, tt.at)
+			if err != nil {
+				t.Fatalf("failed to list due grants: %v", err)
+			}
+
+			assertGrants(t, got, tt.want)
+			for _, grant := range got {
+				if grant.RoleId == "10" && grant.Attempts != tt.failed[grant.UserId] {
+					t.Fatalf("expected %d attempts for user %s, got %d", tt.failed[grant.UserId], grant.UserId, grant.Attempts)
+				}
+			}
+		})
+	}
+}
+
+func assertGrants(t *testing.T, got []*Grant, want []*Grant) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("expected %d grants, got %d", len(want), len(got))
+	}
+
+	for i := range want {
+		g, w := got[i], want[i]
+		if g.RoleId != w.RoleId || g.RoleName != w.RoleName || g.UserId != w.UserId || g.Entitlement != w.Entitlement {
+			t.Fatalf("grant %d: expected %+v, got %+v", i, w, g)
+		}
+		if !g.ExpiresAt.Equal(w.ExpiresAt) {
+			t.Fatalf("grant %d: expected expiry %s, got %s", i, w.ExpiresAt, g.ExpiresAt)
+		}
+	}
+}
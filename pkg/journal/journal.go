@@ -0,0 +1,215 @@
+package journal
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Outcomes recorded for a provisioning action.
+const (
+	OutcomeSucceeded      = "succeeded"
+	OutcomeFailed         = "failed"
+	OutcomeAlreadyExists  = "already_exists"
+	OutcomeAlreadyRevoked = "already_revoked"
+	OutcomeDryRun         = "dry_run"
+)
+
+// Entry is a single line of the journal. Hash covers every other field, including the hash of the previous
+// entry, so removing or editing a line breaks the chain.
+type Entry struct {
+	Sequence      int64     `json:"seq"`
+	Timestamp     time.Time `json:"timestamp"`
+	Action        string    `json:"action"`
+	Principal     string    `json:"principal,omitempty"`
+	Entitlement   string    `json:"entitlement,omitempty"`
+	Resource      string    `json:"resource,omitempty"`
+	PayloadSHA256 string    `json:"payload_sha256,omitempty"`
+	HTTPStatus    int       `json:"http_status,omitempty"`
+	RequestId     string    `json:"request_id,omitempty"`
+	Outcome       string    `json:"outcome"`
+	Error         string    `json:"error,omitempty"`
+	PrevHash      string    `json:"prev_hash"`
+	Hash          string    `json:"hash"`
+}
+
+// Journal is an append-only JSONL file of provisioning actions chained together by their hashes.
+type Journal struct {
+	mutex    sync.Mutex
+	file     *os.File
+	sequence int64
+	lastHash string
+}
+
+// Open opens the journal at path for appending, creating it if needed, and continues its hash chain.
+func Open(path string) (*Journal, error) {
+	last, err := lastEntry(path)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	j := &Journal{file: file}
+	if last != nil {
+		j.sequence = last.Sequence
+		j.lastHash = last.Hash
+	}
+
+	return j, nil
+}
+
+// Append chains the entry to the journal and writes it to disk.
+func (j *Journal) Append(entry Entry) error {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	entry.Sequence = j.sequence + 1
+	entry.PrevHash = j.lastHash
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now().UTC()
+	}
+
+	hash, err := entryHash(entry)
+	if err != nil {
+		return err
+	}
+	entry.Hash = hash
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	_, err = j.file.Write(append(line, '\n'))
+	if err != nil {
+		return err
+	}
+
+	err = j.file.Sync()
+	if err != nil {
+		return err
+	}
+
+	j.sequence = entry.Sequence
+	j.lastHash = entry.Hash
+
+	return nil
+}
+
+// Close closes the underlying journal file.
+func (j *Journal) Close() error {
+	return j.file.Close()
+}
+
+// Verify checks the hash chain of the journal at path and returns the number of entries verified.
+func Verify(path string) (int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	var count int64
+	prevHash := ""
+	err = readEntries(file, func(entry *Entry) error {
+		count++
+
+		if entry.Sequence != count {
+			return fmt.Errorf("entry %d: unexpected sequence number %d", count, entry.Sequence)
+		}
+
+		if entry.PrevHash != prevHash {
+			return fmt.Errorf("entry %d: previous hash does not match, journal was altered", count)
+		}
+
+		hash, err := entryHash(*entry)
+		if err != nil {
+			return err
+		}
+
+		if hash != entry.Hash {
+			return fmt.Errorf("entry %d: hash does not match its content, journal was altered", count)
+		}
+
+		prevHash = entry.Hash
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func entryHash(entry Entry) (string, error) {
+	entry.Hash = ""
+
+	content, err := json.Marshal(entry)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func lastEntry(path string) (*Entry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	var last *Entry
+	err = readEntries(file, func(entry *Entry) error {
+		last = entry
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return last, nil
+}
+
+func readEntries(r io.Reader, f func(entry *Entry) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		content := bytes.TrimSpace(scanner.Bytes())
+		if len(content) == 0 {
+			continue
+		}
+
+		entry := &Entry{}
+		err := json.Unmarshal(content, entry)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+
+		err = f(entry)
+		if err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
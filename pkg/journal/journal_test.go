@@ -0,0 +1,152 @@
+package journal
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeJournal(t *testing.T, entries ...Entry) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	j, err := Open(path)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+	defer j.Close()
+
+	for _, entry := range entries {
+		err := j.Append(entry)
+		if err != nil {
+			t.Fatalf("failed to append entry: %v", err)
+		}
+	}
+
+	return path
+}
+
+func readLines(t *testing.T, path string) [][]byte {
+	t.Helper()
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read journal: %v", err)
+	}
+
+	return bytes.Split(bytes.TrimSpace(content), []byte("\n"))
+}
+
+func writeLines(t *testing.T, path string, lines [][]byte) {
+	t.Helper()
+
+	err := os.WriteFile(path, append(bytes.Join(lines, []byte("\n")), '\n'), 0o600)
+	if err != nil {
+		t.Fatalf("failed to write journal: %v", err)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	entries := []Entry{
+		{Action: "grant", Principal: "user:1", Entitlement: "role:10:member", Outcome: OutcomeSucceeded},
+		{Action: "revoke", Principal: "user:2", Entitlement: "role:10:member", Outcome: OutcomeFailed, Error: "boom"},
+		{Action: "grant", Principal: "user:3", Entitlement: "role:11:admin", Outcome: OutcomeDryRun},
+	}
+
+	tests := []struct {
+		name    string
+		alter   func(lines [][]byte) [][]byte
+		count   int64
+		wantErr string
+	}{
+		{
+			name:  "intact journal",
+			alter: func(lines [][]byte) [][]byte { return lines },
+			count: 3,
+		},
+		{
+			name: "edited entry",
+			alter: func(lines [][]byte) [][]byte {
+				lines[1] = bytes.Replace(lines[1], []byte(`"user:2"`), []byte(`"user:9"`), 1)
+				return lines
+			},
+			wantErr: "entry 2: hash does not match",
+		},
+		{
+			name: "removed entry",
+			alter: func(lines [][]byte) [][]byte {
+				return append(lines[:1], lines[2:]...)
+			},
+			wantErr: "entry 2: unexpected sequence number 3",
+		},
+		{
+			name: "reordered entries",
+			alter: func(lines [][]byte) [][]byte {
+				return [][]byte{lines[1], lines[0], lines[2]}
+			},
+			wantErr: "entry 1: unexpected sequence number 2",
+		},
+		{
+			name: "truncated tail",
+			alter: func(lines [][]byte) [][]byte {
+				return lines[:2]
+			},
+			count: 2,
+		},
+		{
+			name: "malformed line",
+			alter: func(lines [][]byte) [][]byte {
+				lines[2] = []byte("{not json")
+				return lines
+			},
+			wantErr: "line 3:",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeJournal(t, entries...)
+			writeLines(t, path, tt.alter(readLines(t, path)))
+
+			count, err := Verify(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if count != tt.count {
+				t.Fatalf("expected %d entries, got %d", tt.count, count)
+			}
+		})
+	}
+}
+
+func TestOpenContinuesChain(t *testing.T) {
+	path := writeJournal(t, Entry{Action: "grant", Outcome: OutcomeSucceeded})
+
+	j, err := Open(path)
+	if err != nil {
+		t.Fatalf("failed to reopen journal: %v", err)
+	}
+
+	err = j.Append(Entry{Action: "revoke", Outcome: OutcomeAlreadyRevoked})
+	if err != nil {
+		t.Fatalf("failed to append entry: %v", err)
+	}
+	j.Close()
+
+	count, err := Verify(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if count != 2 {
+		t.Fatalf("expected 2 entries, got %d", count)
+	}
+}
@@ -0,0 +1,52 @@
+package onelogin
+
+import (
+	"strconv"
+	"strings"
+)
+
+const customAttributePrefix = "custom_attribute_"
+
+// UserAttributes holds a user as returned by OneLogin, with every attribute the user has.
+type UserAttributes map[string]interface{}
+
+// Id returns the ID of the user.
+func (u UserAttributes) Id() int {
+	id, _ := u["id"].(float64)
+	return int(id)
+}
+
+// Has reports whether the user has the attribute, named as in Value.
+func (u UserAttributes) Has(attribute string) bool {
+	if name, ok := strings.CutPrefix(attribute, customAttributePrefix); ok {
+		customAttributes, _ := u["custom_attributes"].(map[string]interface{})
+		_, ok = customAttributes[name]
+		return ok
+	}
+
+	_, ok := u[attribute]
+	return ok
+}
+
+// Value returns the value of a user attribute as it is named in app parameter mappings,
+// where custom attributes are prefixed with custom_attribute_. Missing attributes are empty.
+func (u UserAttributes) Value(attribute string) string {
+	var value interface{}
+	if name, ok := strings.CutPrefix(attribute, customAttributePrefix); ok {
+		customAttributes, _ := u["custom_attributes"].(map[string]interface{})
+		value = customAttributes[name]
+	} else {
+		value = u[attribute]
+	}
+
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,191 @@
+package onelogin
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MaxRoleUsersBatchSize is the largest number of user IDs sent in a single role users or admins request.
+const MaxRoleUsersBatchSize = 100
+
+type roleBatchKey struct {
+	grant       bool
+	roleId      string
+	entitlement string
+}
+
+type roleBatchResult struct {
+	err      error
+	recorder ResponseRecorder
+}
+
+type roleBatch struct {
+	key     roleBatchKey
+	userIds []string
+	waiters map[string][]chan roleBatchResult
+	timer   *time.Timer
+	// sent is set once the batch is being written, after which its users can no longer be withdrawn.
+	sent bool
+}
+
+// RoleBatcher coalesces grants and revokes of the same role and entitlement made within a short window
+// into array requests. When OneLogin rejects a batch, its users are retried one by one so every caller
+// gets the outcome of its own user.
+type RoleBatcher struct {
+	ctx     context.Context
+	client  *Client
+	window  time.Duration
+	maxSize int
+
+	mutex   sync.Mutex
+	pending map[roleBatchKey]*roleBatch
+}
+
+// NewRoleBatcher returns a batcher that waits up to window for more users to join a batch.
+// Batched requests run with ctx, since they are shared by several callers.
+func NewRoleBatcher(ctx context.Context, client *Client, window time.Duration) *RoleBatcher {
+	return &RoleBatcher{
+		ctx:     ctx,
+		client:  client,
+		window:  window,
+		maxSize: MaxRoleUsersBatchSize,
+		pending: make(map[roleBatchKey]*roleBatch),
+	}
+}
+
+// GrantRole queues the user to be assigned the role entitlement and waits for the batch to complete.
+func (b *RoleBatcher) GrantRole(ctx context.Context, roleId, userId, entitlement string) error {
+	return b.enqueue(ctx, roleBatchKey{grant: true, roleId: roleId, entitlement: entitlement}, userId)
+}
+
+// RevokeRole queues the user to be removed from the role entitlement and waits for the batch to complete.
+func (b *RoleBatcher) RevokeRole(ctx context.Context, roleId, userId, entitlement string) error {
+	return b.enqueue(ctx, roleBatchKey{grant: false, roleId: roleId, entitlement: entitlement}, userId)
+}
+
+func (b *RoleBatcher) enqueue(ctx context.Context, key roleBatchKey, userId string) error {
+	result := make(chan roleBatchResult, 1)
+
+	b.mutex.Lock()
+	batch, ok := b.pending[key]
+	if !ok {
+		batch = &roleBatch{
+			key:     key,
+			waiters: make(map[string][]chan roleBatchResult),
+		}
+		b.pending[key] = batch
+		batch.timer = time.AfterFunc(b.window, func() {
+			b.flush(batch)
+		})
+	}
+
+	if _, queued := batch.waiters[userId]; !queued {
+		batch.userIds = append(batch.userIds, userId)
+	}
+	batch.waiters[userId] = append(batch.waiters[userId], result)
+
+	// a full batch is closed right away so no more users join it
+	full := len(batch.userIds) >= b.maxSize
+	if full {
+		delete(b.pending, key)
+	}
+	b.mutex.Unlock()
+
+	if full && batch.timer.Stop() {
+		go b.flush(batch)
+	}
+
+	var r roleBatchResult
+	select {
+	case <-ctx.Done():
+		if b.withdraw(batch, userId, result) {
+			return ctx.Err()
+		}
+		// the batch is already being written, so report what happened to it rather than a failure
+		r = <-result
+	case r = <-result:
+	}
+
+	if recorder, ok := ctx.Value(recorderKey{}).(*ResponseRecorder); ok && recorder != nil {
+		*recorder = r.recorder
+	}
+	return r.err
+}
+
+// withdraw takes a cancelled caller out of a batch that has not been sent yet, and reports whether it did.
+// The user is only dropped from the batch once no other caller waits on it.
+func (b *RoleBatcher) withdraw(batch *roleBatch, userId string, result chan roleBatchResult) bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if batch.sent {
+		return false
+	}
+
+	waiters := batch.waiters[userId]
+	for i, waiter := range waiters {
+		if waiter == result {
+			waiters = append(waiters[:i], waiters[i+1:]...)
+			break
+		}
+	}
+	if len(waiters) > 0 {
+		batch.waiters[userId] = waiters
+		return true
+	}
+
+	delete(batch.waiters, userId)
+	for i, id := range batch.userIds {
+		if id == userId {
+			batch.userIds = append(batch.userIds[:i], batch.userIds[i+1:]...)
+			break
+		}
+	}
+
+	return true
+}
+
+func (b *RoleBatcher) flush(batch *roleBatch) {
+	b.mutex.Lock()
+	if b.pending[batch.key] == batch {
+		delete(b.pending, batch.key)
+	}
+	batch.sent = true
+	b.mutex.Unlock()
+
+	// every caller withdrew before the batch was sent
+	if len(batch.userIds) == 0 {
+		return
+	}
+
+	recorder := &ResponseRecorder{}
+	err := b.send(WithResponseRecorder(b.ctx, recorder), batch.key, batch.userIds)
+	if err == nil || len(batch.userIds) == 1 {
+		for _, userId := range batch.userIds {
+			b.notify(batch, userId, roleBatchResult{err: err, recorder: *recorder})
+		}
+		return
+	}
+
+	// OneLogin rejected the batch, find out which users it was rejected for
+	for _, userId := range batch.userIds {
+		userRecorder := &ResponseRecorder{}
+		userErr := b.send(WithResponseRecorder(b.ctx, userRecorder), batch.key, []string{userId})
+		b.notify(batch, userId, roleBatchResult{err: userErr, recorder: *userRecorder})
+	}
+}
+
+func (b *RoleBatcher) send(ctx context.Context, key roleBatchKey, userIds []string) error {
+	if key.grant {
+		return b.client.GrantRoleUsers(ctx, key.roleId, userIds, key.entitlement)
+	}
+
+	return b.client.RevokeRoleUsers(ctx, key.roleId, userIds, key.entitlement)
+}
+
+func (b *RoleBatcher) notify(batch *roleBatch, userId string, result roleBatchResult) {
+	for _, waiter := range batch.waiters[userId] {
+		waiter <- result
+	}
+}
@@ -0,0 +1,194 @@
+package onelogin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// fakeRoleAPI answers role users and admins requests, rejecting any request that names a rejected user.
+type fakeRoleAPI struct {
+	mutex    sync.Mutex
+	rejected map[string]bool
+	requests [][]string
+}
+
+func (f *fakeRoleAPI) client() *Client {
+	return &Client{
+		httpClient: &http.Client{Transport: roundTripFunc(f.roundTrip)},
+		token:      "token",
+		subdomain:  "example",
+	}
+}
+
+func (f *fakeRoleAPI) roundTrip(req *http.Request) (*http.Response, error) {
+	var userIds []string
+	err := json.NewDecoder(req.Body).Decode(&userIds)
+	if err != nil {
+		return nil, err
+	}
+
+	f.mutex.Lock()
+	f.requests = append(f.requests, userIds)
+	f.mutex.Unlock()
+
+	status := http.StatusOK
+	for _, userId := range userIds {
+		if f.rejected[userId] {
+			status = http.StatusBadRequest
+		}
+	}
+
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{requestIdHeader: []string{strings.Join(userIds, ",")}},
+		Body:       io.NopCloser(strings.NewReader("[]")),
+		Request:    req,
+	}, nil
+}
+
+func TestRoleBatcherAttributesFailures(t *testing.T) {
+	tests := []struct {
+		name     string
+		grant    bool
+		userIds  []string
+		rejected []string
+		requests int
+	}{
+		{
+			name:     "batch succeeds",
+			grant:    true,
+			userIds:  []string{"1", "2", "3"},
+			requests: 1,
+		},
+		{
+			name:     "batch rejected for one user",
+			grant:    true,
+			userIds:  []string{"1", "2", "3"},
+			rejected: []string{"2"},
+			requests: 4,
+		},
+		{
+			name:     "batch rejected for every user",
+			grant:    false,
+			userIds:  []string{"1", "2"},
+			rejected: []string{"1", "2"},
+			requests: 3,
+		},
+		{
+			name:     "single user rejected",
+			grant:    false,
+			userIds:  []string{"1"},
+			rejected: []string{"1"},
+			requests: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &fakeRoleAPI{rejected: make(map[string]bool)}
+			for _, userId := range tt.rejected {
+				api.rejected[userId] = true
+			}
+
+			// the batch is flushed once every user joined it, never by the window
+			batcher := NewRoleBatcher(context.Background(), api.client(), time.Hour)
+			batcher.maxSize = len(tt.userIds)
+
+			errs := make([]error, len(tt.userIds))
+			recorders := make([]ResponseRecorder, len(tt.userIds))
+			var wg sync.WaitGroup
+			for i, userId := range tt.userIds {
+				wg.Add(1)
+				go func(i int, userId string) {
+					defer wg.Done()
+					ctx := WithResponseRecorder(context.Background(), &recorders[i])
+					if tt.grant {
+						errs[i] = batcher.GrantRole(ctx, "10", userId, "member")
+					} else {
+						errs[i] = batcher.RevokeRole(ctx, "10", userId, "member")
+					}
+				}(i, userId)
+			}
+			wg.Wait()
+
+			if len(api.requests) != tt.requests {
+				t.Fatalf("expected %d requests, got %d: %v", tt.requests, len(api.requests), api.requests)
+			}
+			if len(api.requests[0]) != len(tt.userIds) {
+				t.Fatalf("expected the first request to batch %d users, got %v", len(tt.userIds), api.requests[0])
+			}
+
+			for i, userId := range tt.userIds {
+				if api.rejected[userId] {
+					var httpErr *HTTPError
+					if !errors.As(errs[i], &httpErr) || httpErr.StatusCode != http.StatusBadRequest {
+						t.Fatalf("user %s: expected a 400 error, got %v", userId, errs[i])
+					}
+				} else if errs[i] != nil {
+					t.Fatalf("user %s: unexpected error: %v", userId, errs[i])
+				}
+
+				// a retried user is audited with its own request
+				wantRequestId := strings.Join(api.requests[0], ",")
+				if tt.requests > 1 {
+					wantRequestId = userId
+				}
+				if recorders[i].RequestId != wantRequestId {
+					t.Fatalf("user %s: expected request %q to be recorded, got %q", userId, wantRequestId, recorders[i].RequestId)
+				}
+			}
+		})
+	}
+}
+
+func TestRoleBatcherWithdrawsCancelledCallers(t *testing.T) {
+	api := &fakeRoleAPI{rejected: make(map[string]bool)}
+	batcher := NewRoleBatcher(context.Background(), api.client(), time.Hour)
+	batcher.maxSize = 2
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := batcher.GrantRole(ctx, "10", "1", "member")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the cancelled caller to get %v, got %v", context.Canceled, err)
+	}
+
+	errs := make([]error, 2)
+	var wg sync.WaitGroup
+	for i, userId := range []string{"2", "3"} {
+		wg.Add(1)
+		go func(i int, userId string) {
+			defer wg.Done()
+			errs[i] = batcher.GrantRole(context.Background(), "10", userId, "member")
+		}(i, userId)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("caller %d: unexpected error: %v", i, err)
+		}
+	}
+
+	if len(api.requests) != 1 || len(api.requests[0]) != 2 {
+		t.Fatalf("expected a single request for the remaining users, got %v", api.requests)
+	}
+	for _, userId := range api.requests[0] {
+		if userId == "1" {
+			t.Fatalf("the withdrawn user was written: %v", api.requests[0])
+		}
+	}
+}
@@ -9,9 +9,6 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
-
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
 )
 
 const (
@@ -24,16 +21,51 @@ const (
 	APIBaseURL        = BaseURL + "api/2/"
 	UsersBaseURL      = APIBaseURL + "users"
 	UserBaseUrl       = UsersBaseURL + "/%s"
+	UserRolesBaseURL  = UserBaseUrl + "/roles"
 	RolesBaseURL      = APIBaseURL + "roles"
+	RoleBaseURL       = RolesBaseURL + "/%s"
 	RoleUsersBaseURL  = APIBaseURL + "roles/%s/users"
 	RoleAdminsBaseURL = APIBaseURL + "roles/%s/admins"
 	RoleAppsBaseURL   = APIBaseURL + "roles/%s/apps"
 	AppsBaseURL       = APIBaseURL + "apps"
+	AppBaseURL        = AppsBaseURL + "/%s"
+	AppRulesBaseURL   = AppBaseURL + "/rules"
 	AppUsersBaseURL   = APIBaseURL + "apps/%s/users"
 	GroupsBaseURL     = APIBaseV1URL + "groups"
 	ConnectorsBaseURL = APIBaseURL + "connectors"
+
+	PrivilegesBaseURL     = APIBaseURL + "privileges"
+	PrivilegeUsersBaseURL = PrivilegesBaseURL + "/%s/users"
+	PrivilegeUserBaseURL  = PrivilegeUsersBaseURL + "/%s"
+	PrivilegeRolesBaseURL = PrivilegesBaseURL + "/%s/roles"
+	PrivilegeRoleBaseURL  = PrivilegeRolesBaseURL + "/%s"
+
+	MappingsBaseURL = APIBaseURL + "mappings"
+	HooksBaseURL    = APIBaseURL + "hooks"
+	HookBaseURL     = HooksBaseURL + "/%s"
+
+	TrustedIdpsBaseURL              = APIBaseURL + "trusted_idps"
+	SelfRegistrationProfilesBaseURL = APIBaseURL + "self_registration_profiles"
+
+	PoliciesBaseURL = APIBaseURL + "policies"
+	PolicyBaseURL   = PoliciesBaseURL + "/%s"
+
+	BrandsBaseURL         = APIBaseURL + "branding/brands"
+	BrandTemplatesBaseURL = BrandsBaseURL + "/%s/templates"
+	BrandAppsBaseURL      = BrandsBaseURL + "/%s/apps"
+
+	APIAuthorizationsBaseURL       = APIBaseURL + "api_authorizations"
+	APIAuthorizationScopesBaseURL  = APIAuthorizationsBaseURL + "/%s/scopes"
+	APIAuthorizationClientsBaseURL = APIAuthorizationsBaseURL + "/%s/clients"
+	APIAuthorizationClientBaseURL  = APIAuthorizationClientsBaseURL + "/%s"
+
+	EventsBaseURL     = APIBaseV1URL + "events"
+	EventTypesBaseURL = EventsBaseURL + "/types"
 )
 
+// MaxAssetSize bounds the size of assets, such as app icons, downloaded from OneLogin.
+const MaxAssetSize = 1 << 20
+
 type Client struct {
 	httpClient *http.Client
 	token      string
@@ -76,6 +108,28 @@ func (c *Client) GetUsers(ctx context.Context, paginationVars PaginationVars, gr
 	return usersResponse, nextPage, nil
 }
 
+// GetUsersAttributes returns users with all of their attributes, custom attributes included.
+func (c *Client) GetUsersAttributes(ctx context.Context, paginationVars PaginationVars) ([]UserAttributes, string, error) {
+	var usersResponse []UserAttributes
+
+	nextPage, err := c.doRequest(
+		ctx,
+		fmt.Sprintf(UsersBaseURL, c.subdomain),
+		http.MethodGet,
+		&usersResponse,
+		nil,
+		[]QueryParam{
+			&paginationVars,
+		}...,
+	)
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	return usersResponse, nextPage, nil
+}
+
 func (c *Client) GetUserByID(ctx context.Context, userID int) (*User, error) {
 	var userResponse *User
 
@@ -115,6 +169,67 @@ func (c *Client) GetApps(ctx context.Context, paginationVars PaginationVars) ([]
 	return appsResponse, nextPage, nil
 }
 
+// GetConnectorApps returns the apps built from the given catalog connector.
+func (c *Client) GetConnectorApps(ctx context.Context, connectorId string, paginationVars PaginationVars) ([]App, string, error) {
+	var appsResponse []App
+
+	nextPage, err := c.doRequest(
+		ctx,
+		fmt.Sprintf(AppsBaseURL, c.subdomain),
+		http.MethodGet,
+		&appsResponse,
+		nil,
+		[]QueryParam{
+			&paginationVars,
+			&AppFilterVars{ConnectorId: connectorId},
+		}...,
+	)
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	return appsResponse, nextPage, nil
+}
+
+// GetApp returns an app along with its parameters.
+func (c *Client) GetApp(ctx context.Context, appId string) (*AppDetail, error) {
+	var appResponse *AppDetail
+
+	_, err := c.doRequest(
+		ctx,
+		fmt.Sprintf(AppBaseURL, c.subdomain, appId),
+		http.MethodGet,
+		&appResponse,
+		nil,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return appResponse, nil
+}
+
+// GetAppRules returns the provisioning rules of an app, which OneLogin lists without pagination.
+func (c *Client) GetAppRules(ctx context.Context, appId string) ([]AppRule, error) {
+	var appRulesResponse []AppRule
+
+	_, err := c.doRequest(
+		ctx,
+		fmt.Sprintf(AppRulesBaseURL, c.subdomain, appId),
+		http.MethodGet,
+		&appRulesResponse,
+		nil,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return appRulesResponse, nil
+}
+
 func (c *Client) GetAppUsers(ctx context.Context, appId string, paginationVars PaginationVars) ([]User, string, error) {
 	var appUsersResponse []User
 
@@ -184,6 +299,87 @@ func (c *Client) GetRoles(ctx context.Context, paginationVars PaginationVars) ([
 	return rolesResponse, nextPage, nil
 }
 
+// GetRole returns a single role.
+func (c *Client) GetRole(ctx context.Context, roleId string) (*Role, error) {
+	var roleResponse *Role
+
+	_, err := c.doRequest(
+		ctx,
+		fmt.Sprintf(RoleBaseURL, c.subdomain, roleId),
+		http.MethodGet,
+		&roleResponse,
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return roleResponse, nil
+}
+
+func (c *Client) CreateRole(ctx context.Context, body *RoleBody) (int, error) {
+	var createRoleResponse []BaseResource
+
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = c.doRequest(
+		ctx,
+		fmt.Sprintf(RolesBaseURL, c.subdomain),
+		http.MethodPost,
+		&createRoleResponse,
+		payload,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(createRoleResponse) == 0 {
+		return 0, fmt.Errorf("create role response is missing role id")
+	}
+
+	return createRoleResponse[0].Id, nil
+}
+
+func (c *Client) UpdateRole(ctx context.Context, roleId string, body *RoleBody) error {
+	var updateRoleResponse BaseResource
+
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.doRequest(
+		ctx,
+		fmt.Sprintf(RoleBaseURL, c.subdomain, roleId),
+		http.MethodPut,
+		&updateRoleResponse,
+		payload,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *Client) DeleteRole(ctx context.Context, roleId string) error {
+	_, err := c.doRequest(
+		ctx,
+		fmt.Sprintf(RoleBaseURL, c.subdomain, roleId),
+		http.MethodDelete,
+		nil,
+		nil,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (c *Client) GetRoleUsers(ctx context.Context, roleId string, paginationVars PaginationVars) ([]UserUnderRole, string, error) {
 	var roleUsersResponse []UserUnderRole
 
@@ -205,6 +401,37 @@ func (c *Client) GetRoleUsers(ctx context.Context, roleId string, paginationVars
 	return roleUsersResponse, nextPage, nil
 }
 
+// GetUserRoleIds returns the IDs of the roles the user is a member of.
+func (c *Client) GetUserRoleIds(ctx context.Context, userId string) ([]int, error) {
+	var rv []int
+	cursor := ""
+
+	for {
+		var userRolesResponse []int
+
+		nextPage, err := c.doRequest(
+			ctx,
+			fmt.Sprintf(UserRolesBaseURL, c.subdomain, userId),
+			http.MethodGet,
+			&userRolesResponse,
+			nil,
+			[]QueryParam{
+				&PaginationVars{Cursor: cursor},
+			}...,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		rv = append(rv, userRolesResponse...)
+		if nextPage == "" {
+			return rv, nil
+		}
+		cursor = nextPage
+	}
+}
+
 func (c *Client) GetRoleAdmins(ctx context.Context, roleId string, paginationVars PaginationVars) ([]UserUnderRole, string, error) {
 	var roleAdminsResponse []UserUnderRole
 
@@ -248,6 +475,11 @@ func (c *Client) GetRoleApps(ctx context.Context, roleId string, paginationVars
 }
 
 func (c *Client) GrantRole(ctx context.Context, roleId, userId, entitlement string) error {
+	return c.GrantRoleUsers(ctx, roleId, []string{userId}, entitlement)
+}
+
+// GrantRoleUsers assigns several users to a role, or makes them its admins, in a single request.
+func (c *Client) GrantRoleUsers(ctx context.Context, roleId string, userIds []string, entitlement string) error {
 	var assignRoleResponse []BaseResource
 	var roleUrl string
 
@@ -257,7 +489,7 @@ func (c *Client) GrantRole(ctx context.Context, roleId, userId, entitlement stri
 		roleUrl = fmt.Sprintf(RoleUsersBaseURL, c.subdomain, roleId)
 	}
 
-	payload, e := json.Marshal([]string{userId})
+	payload, e := json.Marshal(userIds)
 	if e != nil {
 		return e
 	}
@@ -277,6 +509,11 @@ func (c *Client) GrantRole(ctx context.Context, roleId, userId, entitlement stri
 }
 
 func (c *Client) RevokeRole(ctx context.Context, roleId, userId, entitlement string) error {
+	return c.RevokeRoleUsers(ctx, roleId, []string{userId}, entitlement)
+}
+
+// RevokeRoleUsers removes several users, or admins, from a role in a single request.
+func (c *Client) RevokeRoleUsers(ctx context.Context, roleId string, userIds []string, entitlement string) error {
 	var roleUrl string
 	if entitlement == "admin" {
 		roleUrl = fmt.Sprintf(RoleAdminsBaseURL, c.subdomain, roleId)
@@ -284,7 +521,7 @@ func (c *Client) RevokeRole(ctx context.Context, roleId, userId, entitlement str
 		roleUrl = fmt.Sprintf(RoleUsersBaseURL, c.subdomain, roleId)
 	}
 
-	payload, e := json.Marshal([]string{userId})
+	payload, e := json.Marshal(userIds)
 	if e != nil {
 		return e
 	}
@@ -302,106 +539,683 @@ func (c *Client) RevokeRole(ctx context.Context, roleId, userId, entitlement str
 	return nil
 }
 
-// ValidateScope checks if user has 'Manage all' scope needed to read/write all resources.
-func (c *Client) ValidateScope(ctx context.Context, paginationVars PaginationVars) (string, error) {
-	var response []BaseResource
-	nextPage, err := c.doRequest(
+func (c *Client) GetPrivileges(ctx context.Context) ([]Privilege, error) {
+	var privilegesResponse []Privilege
+
+	_, err := c.doRequest(
 		ctx,
-		fmt.Sprintf(ConnectorsBaseURL, c.subdomain),
+		fmt.Sprintf(PrivilegesBaseURL, c.subdomain),
 		http.MethodGet,
-		&response,
+		&privilegesResponse,
 		nil,
-		[]QueryParam{
-			&paginationVars,
-		}...,
 	)
 
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return nextPage, nil
+	return privilegesResponse, nil
 }
 
-func generateToken(ctx context.Context, httpClient *http.Client, clientId, clientSecret, subdomain string) (string, error) {
-	var credentialsResponse Credentials
-	var body io.Reader
-
-	// set request body
-	jsonBody, err := json.Marshal(NewCredentialsGrant())
-	if err != nil {
-		return "", err
+func (c *Client) GetPrivilegeUsers(ctx context.Context, privilegeId string) ([]int, error) {
+	var privilegeUsersResponse struct {
+		Users []int `json:"users"`
 	}
-	body = bytes.NewBuffer(jsonBody)
 
-	// create request
-	req, err := http.NewRequestWithContext(
+	_, err := c.doRequest(
 		ctx,
-		http.MethodPost,
-		fmt.Sprintf(GenerateTokenBaseURL, subdomain),
-		body,
+		fmt.Sprintf(PrivilegeUsersBaseURL, c.subdomain, privilegeId),
+		http.MethodGet,
+		&privilegeUsersResponse,
+		nil,
 	)
+
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	// set request headers
-	req.Header.Set("Authorization", fmt.Sprintf("client_id:%s,client_secret:%s", clientId, clientSecret))
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "application/json")
+	return privilegeUsersResponse.Users, nil
+}
+
+func (c *Client) GetPrivilegeRoles(ctx context.Context, privilegeId string) ([]int, error) {
+	var privilegeRolesResponse struct {
+		Roles []int `json:"roles"`
+	}
+
+	_, err := c.doRequest(
+		ctx,
+		fmt.Sprintf(PrivilegeRolesBaseURL, c.subdomain, privilegeId),
+		http.MethodGet,
+		&privilegeRolesResponse,
+		nil,
+	)
 
-	// send the request
-	rawResponse, err := httpClient.Do(req)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	defer rawResponse.Body.Close()
+	return privilegeRolesResponse.Roles, nil
+}
 
-	if rawResponse.StatusCode >= 300 {
-		return "", status.Error(codes.Code(rawResponse.StatusCode), "Request failed") //nolint:gosec // safe conversion: HTTP status code is always in range 0-599
+func (c *Client) AssignPrivilegeUser(ctx context.Context, privilegeId string, userId int) error {
+	var assignResponse interface{}
+
+	payload, err := json.Marshal(map[string][]int{"users": {userId}})
+	if err != nil {
+		return err
 	}
 
-	if err := json.NewDecoder(rawResponse.Body).Decode(&credentialsResponse); err != nil {
-		return "", err
+	_, err = c.doRequest(
+		ctx,
+		fmt.Sprintf(PrivilegeUsersBaseURL, c.subdomain, privilegeId),
+		http.MethodPost,
+		&assignResponse,
+		payload,
+	)
+	if err != nil {
+		return err
 	}
 
-	return credentialsResponse.AccessToken, nil
+	return nil
 }
 
-func (c *Client) doRequest(
-	ctx context.Context,
-	urlAddress string,
-	method string,
-	resourceResponse interface{},
-	payload []byte,
-	paramOptions ...QueryParam,
-) (string, error) {
-	req, err := http.NewRequestWithContext(ctx, method, urlAddress, bytes.NewReader(payload))
+func (c *Client) RemovePrivilegeUser(ctx context.Context, privilegeId string, userId int) error {
+	_, err := c.doRequest(
+		ctx,
+		fmt.Sprintf(PrivilegeUserBaseURL, c.subdomain, privilegeId, strconv.Itoa(userId)),
+		http.MethodDelete,
+		nil,
+		nil,
+	)
 	if err != nil {
-		return "", err
+		return err
 	}
 
-	queryParams := url.Values{}
-	for _, queryParam := range paramOptions {
-		queryParam.setup(&queryParams)
+	return nil
+}
+
+func (c *Client) AssignPrivilegeRole(ctx context.Context, privilegeId string, roleId int) error {
+	var assignResponse interface{}
+
+	payload, err := json.Marshal(map[string][]int{"roles": {roleId}})
+	if err != nil {
+		return err
 	}
 
-	req.URL.RawQuery = queryParams.Encode()
+	_, err = c.doRequest(
+		ctx,
+		fmt.Sprintf(PrivilegeRolesBaseURL, c.subdomain, privilegeId),
+		http.MethodPost,
+		&assignResponse,
+		payload,
+	)
+	if err != nil {
+		return err
+	}
 
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.token))
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "application/json")
+	return nil
+}
 
-	rawResponse, err := c.httpClient.Do(req)
+func (c *Client) RemovePrivilegeRole(ctx context.Context, privilegeId string, roleId int) error {
+	_, err := c.doRequest(
+		ctx,
+		fmt.Sprintf(PrivilegeRoleBaseURL, c.subdomain, privilegeId, strconv.Itoa(roleId)),
+		http.MethodDelete,
+		nil,
+		nil,
+	)
 	if err != nil {
-		return "", err
+		return err
 	}
 
-	defer rawResponse.Body.Close()
+	return nil
+}
+
+// GetMappings returns the enabled user mappings, which OneLogin lists without pagination.
+func (c *Client) GetMappings(ctx context.Context) ([]Mapping, error) {
+	var mappingsResponse []Mapping
+
+	_, err := c.doRequest(
+		ctx,
+		fmt.Sprintf(MappingsBaseURL, c.subdomain),
+		http.MethodGet,
+		&mappingsResponse,
+		nil,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return mappingsResponse, nil
+}
+
+// GetAPIAuthorizations returns the API authorization servers, which OneLogin lists without pagination.
+func (c *Client) GetAPIAuthorizations(ctx context.Context) ([]APIAuthorization, error) {
+	var authorizationsResponse []APIAuthorization
+
+	_, err := c.doRequest(
+		ctx,
+		fmt.Sprintf(APIAuthorizationsBaseURL, c.subdomain),
+		http.MethodGet,
+		&authorizationsResponse,
+		nil,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return authorizationsResponse, nil
+}
+
+func (c *Client) GetAPIScopes(ctx context.Context, authorizationId string) ([]APIScope, error) {
+	var scopesResponse []APIScope
+
+	_, err := c.doRequest(
+		ctx,
+		fmt.Sprintf(APIAuthorizationScopesBaseURL, c.subdomain, authorizationId),
+		http.MethodGet,
+		&scopesResponse,
+		nil,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return scopesResponse, nil
+}
+
+// GetAPIClients returns the client apps of an API authorization server along with their scopes.
+func (c *Client) GetAPIClients(ctx context.Context, authorizationId string) ([]APIClient, error) {
+	var clientsResponse []APIClient
+
+	_, err := c.doRequest(
+		ctx,
+		fmt.Sprintf(APIAuthorizationClientsBaseURL, c.subdomain, authorizationId),
+		http.MethodGet,
+		&clientsResponse,
+		nil,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return clientsResponse, nil
+}
+
+// AddAPIClient authorizes an app as a client of the API authorization server with the given scopes.
+func (c *Client) AddAPIClient(ctx context.Context, authorizationId string, appId int, scopeIds []int) error {
+	var addResponse interface{}
+
+	payload, err := json.Marshal(&APIClientBody{AppId: appId, Scopes: scopeIds})
+	if err != nil {
+		return err
+	}
+
+	_, err = c.doRequest(
+		ctx,
+		fmt.Sprintf(APIAuthorizationClientsBaseURL, c.subdomain, authorizationId),
+		http.MethodPost,
+		&addResponse,
+		payload,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpdateAPIClientScopes replaces the scopes granted to a client app of the API authorization server.
+func (c *Client) UpdateAPIClientScopes(ctx context.Context, authorizationId string, appId int, scopeIds []int) error {
+	var updateResponse interface{}
+
+	// an empty list must be sent as such, since the client keeps its scopes when they are null
+	if scopeIds == nil {
+		scopeIds = []int{}
+	}
+
+	payload, err := json.Marshal(&APIClientBody{Scopes: scopeIds})
+	if err != nil {
+		return err
+	}
+
+	_, err = c.doRequest(
+		ctx,
+		fmt.Sprintf(APIAuthorizationClientBaseURL, c.subdomain, authorizationId, strconv.Itoa(appId)),
+		http.MethodPut,
+		&updateResponse,
+		payload,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetHooks returns the Smart Hooks, which OneLogin lists without pagination.
+func (c *Client) GetHooks(ctx context.Context) ([]Hook, error) {
+	var hooksResponse []Hook
+
+	_, err := c.doRequest(
+		ctx,
+		fmt.Sprintf(HooksBaseURL, c.subdomain),
+		http.MethodGet,
+		&hooksResponse,
+		nil,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return hooksResponse, nil
+}
+
+// GetHook returns a Smart Hook along with its function source.
+func (c *Client) GetHook(ctx context.Context, hookId string) (*Hook, error) {
+	var hookResponse *Hook
+
+	_, err := c.doRequest(
+		ctx,
+		fmt.Sprintf(HookBaseURL, c.subdomain, hookId),
+		http.MethodGet,
+		&hookResponse,
+		nil,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return hookResponse, nil
+}
+
+// GetTrustedIdps returns the trusted identity providers, which OneLogin lists without pagination.
+func (c *Client) GetTrustedIdps(ctx context.Context) ([]TrustedIdp, error) {
+	var trustedIdpsResponse []TrustedIdp
+
+	_, err := c.doRequest(
+		ctx,
+		fmt.Sprintf(TrustedIdpsBaseURL, c.subdomain),
+		http.MethodGet,
+		&trustedIdpsResponse,
+		nil,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return trustedIdpsResponse, nil
+}
+
+// GetSelfRegistrationProfiles returns the self-registration profiles, which OneLogin lists without pagination.
+func (c *Client) GetSelfRegistrationProfiles(ctx context.Context) ([]SelfRegistrationProfile, error) {
+	var profilesResponse []SelfRegistrationProfile
+
+	_, err := c.doRequest(
+		ctx,
+		fmt.Sprintf(SelfRegistrationProfilesBaseURL, c.subdomain),
+		http.MethodGet,
+		&profilesResponse,
+		nil,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return profilesResponse, nil
+}
+
+// GetBrands returns the account brands, which OneLogin lists without pagination.
+func (c *Client) GetBrands(ctx context.Context) ([]Brand, error) {
+	var brandsResponse []Brand
+
+	_, err := c.doRequest(
+		ctx,
+		fmt.Sprintf(BrandsBaseURL, c.subdomain),
+		http.MethodGet,
+		&brandsResponse,
+		nil,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return brandsResponse, nil
+}
+
+// GetBrandTemplates returns the message templates of a brand, without their contents.
+func (c *Client) GetBrandTemplates(ctx context.Context, brandId string) ([]BrandTemplate, error) {
+	var templatesResponse []BrandTemplate
+
+	_, err := c.doRequest(
+		ctx,
+		fmt.Sprintf(BrandTemplatesBaseURL, c.subdomain, brandId),
+		http.MethodGet,
+		&templatesResponse,
+		nil,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return templatesResponse, nil
+}
+
+func (c *Client) GetBrandApps(ctx context.Context, brandId string, paginationVars PaginationVars) ([]App, string, error) {
+	var brandAppsResponse []App
+
+	nextPage, err := c.doRequest(
+		ctx,
+		fmt.Sprintf(BrandAppsBaseURL, c.subdomain, brandId),
+		http.MethodGet,
+		&brandAppsResponse,
+		nil,
+		[]QueryParam{
+			&paginationVars,
+		}...,
+	)
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	return brandAppsResponse, nextPage, nil
+}
+
+// GetPolicies returns the security policies of the given type, user or app.
+func (c *Client) GetPolicies(ctx context.Context, policyType string) ([]Policy, error) {
+	var policiesResponse []Policy
+
+	_, err := c.doRequest(
+		ctx,
+		fmt.Sprintf(PoliciesBaseURL, c.subdomain),
+		http.MethodGet,
+		&policiesResponse,
+		nil,
+		[]QueryParam{
+			&PolicyFilterVars{Type: policyType},
+		}...,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return policiesResponse, nil
+}
+
+// GetPolicy returns a security policy along with all of its settings.
+func (c *Client) GetPolicy(ctx context.Context, policyId string) (*Policy, error) {
+	var policyResponse *Policy
+
+	_, err := c.doRequest(
+		ctx,
+		fmt.Sprintf(PolicyBaseURL, c.subdomain, policyId),
+		http.MethodGet,
+		&policyResponse,
+		nil,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return policyResponse, nil
+}
+
+// GetCatalogConnectors returns the app catalog connectors apps are built from.
+func (c *Client) GetCatalogConnectors(ctx context.Context, paginationVars PaginationVars) ([]CatalogConnector, string, error) {
+	var connectorsResponse []CatalogConnector
+
+	nextPage, err := c.doRequest(
+		ctx,
+		fmt.Sprintf(ConnectorsBaseURL, c.subdomain),
+		http.MethodGet,
+		&connectorsResponse,
+		nil,
+		[]QueryParam{
+			&paginationVars,
+		}...,
+	)
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	return connectorsResponse, nextPage, nil
+}
+
+// GetEvents returns a page of the event log matching the filter, oldest first.
+func (c *Client) GetEvents(ctx context.Context, filter EventFilterVars, paginationVars PaginationVars) ([]Event, string, error) {
+	var eventsResponse struct {
+		Data       []Event    `json:"data"`
+		Pagination Pagination `json:"pagination"`
+	}
+
+	_, err := c.doRequest(
+		ctx,
+		fmt.Sprintf(EventsBaseURL, c.subdomain),
+		http.MethodGet,
+		&eventsResponse,
+		nil,
+		[]QueryParam{
+			&paginationVars,
+			&filter,
+		}...,
+	)
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	// the v1 events API returns the cursor in the response body rather than the after-cursor header
+	return eventsResponse.Data, eventsResponse.Pagination.AfterCursor, nil
+}
+
+// GetEventTypes returns the catalog of event types the event log refers to by ID.
+func (c *Client) GetEventTypes(ctx context.Context) (EventTypes, error) {
+	rv := make(EventTypes)
+	cursor := ""
+
+	for {
+		var eventTypesResponse struct {
+			Data       []EventType `json:"data"`
+			Pagination Pagination  `json:"pagination"`
+		}
+
+		_, err := c.doRequest(
+			ctx,
+			fmt.Sprintf(EventTypesBaseURL, c.subdomain),
+			http.MethodGet,
+			&eventTypesResponse,
+			nil,
+			[]QueryParam{
+				&PaginationVars{V1Cursor: cursor},
+			}...,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, eventType := range eventTypesResponse.Data {
+			rv[eventType.Id] = eventType
+		}
+
+		cursor = eventTypesResponse.Pagination.AfterCursor
+		if cursor == "" {
+			return rv, nil
+		}
+	}
+}
+
+// AdminURL returns the URL of a page of the OneLogin admin console of the tenant.
+func (c *Client) AdminURL(path string, args ...interface{}) string {
+	return fmt.Sprintf(BaseURL, c.subdomain) + fmt.Sprintf(path, args...)
+}
+
+// ValidateScope checks if user has 'Manage all' scope needed to read/write all resources.
+func (c *Client) ValidateScope(ctx context.Context, paginationVars PaginationVars) (string, error) {
+	var response []BaseResource
+	nextPage, err := c.doRequest(
+		ctx,
+		fmt.Sprintf(ConnectorsBaseURL, c.subdomain),
+		http.MethodGet,
+		&response,
+		nil,
+		[]QueryParam{
+			&paginationVars,
+		}...,
+	)
+
+	if err != nil {
+		return "", err
+	}
+
+	return nextPage, nil
+}
+
+// GetAsset downloads an asset such as an app icon and returns it along with its content type.
+// The access token is only sent to the OneLogin tenant itself, never to the CDN hosts
+// icons are usually served from.
+func (c *Client) GetAsset(ctx context.Context, assetURL string) ([]byte, string, error) {
+	parsedURL, err := url.Parse(assetURL)
+	if err != nil {
+		return nil, "", err
+	}
+	if parsedURL.Scheme != "https" {
+		return nil, "", fmt.Errorf("refusing to download asset over %s", parsedURL.Scheme)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, assetURL, nil)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if parsedURL.Host == fmt.Sprintf("%s.onelogin.com", c.subdomain) {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.token))
+	}
+
+	rawResponse, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+
+	defer rawResponse.Body.Close()
+
+	if rawResponse.StatusCode >= 300 {
+		return nil, "", newHTTPError(rawResponse)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(rawResponse.Body, MaxAssetSize+1))
+	if err != nil {
+		return nil, "", err
+	}
+	if len(data) > MaxAssetSize {
+		return nil, "", fmt.Errorf("asset exceeds %d bytes", MaxAssetSize)
+	}
+
+	contentType := rawResponse.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+
+	return data, contentType, nil
+}
+
+func generateToken(ctx context.Context, httpClient *http.Client, clientId, clientSecret, subdomain string) (string, error) {
+	var credentialsResponse Credentials
+	var body io.Reader
+
+	// set request body
+	jsonBody, err := json.Marshal(NewCredentialsGrant())
+	if err != nil {
+		return "", err
+	}
+	body = bytes.NewBuffer(jsonBody)
+
+	// create request
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		fmt.Sprintf(GenerateTokenBaseURL, subdomain),
+		body,
+	)
+	if err != nil {
+		return "", err
+	}
+
+	// set request headers
+	req.Header.Set("Authorization", fmt.Sprintf("client_id:%s,client_secret:%s", clientId, clientSecret))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	// send the request
+	rawResponse, err := httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+
+	defer rawResponse.Body.Close()
+
+	if rawResponse.StatusCode >= 300 {
+		return "", newHTTPError(rawResponse)
+	}
+
+	if err := json.NewDecoder(rawResponse.Body).Decode(&credentialsResponse); err != nil {
+		return "", err
+	}
+
+	return credentialsResponse.AccessToken, nil
+}
+
+func (c *Client) doRequest(
+	ctx context.Context,
+	urlAddress string,
+	method string,
+	resourceResponse interface{},
+	payload []byte,
+	paramOptions ...QueryParam,
+) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, method, urlAddress, bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+
+	queryParams := url.Values{}
+	for _, queryParam := range paramOptions {
+		queryParam.setup(&queryParams)
+	}
+
+	req.URL.RawQuery = queryParams.Encode()
+
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.token))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	rawResponse, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+
+	defer rawResponse.Body.Close()
+
+	if method != http.MethodGet {
+		recordResponse(ctx, payload, rawResponse)
+	}
 
 	if rawResponse.StatusCode >= 300 {
-		return "", status.Error(codes.Code(rawResponse.StatusCode), "Request failed") //nolint:gosec // safe conversion: HTTP status code is always in range 0-599
+		return "", newHTTPError(rawResponse)
 	}
 
 	if method != http.MethodDelete {
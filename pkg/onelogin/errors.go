@@ -0,0 +1,76 @@
+package onelogin
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// HTTPError is returned when OneLogin answers a request with an error status.
+type HTTPError struct {
+	StatusCode int
+	Method     string
+	Path       string
+}
+
+func newHTTPError(response *http.Response) *HTTPError {
+	rv := &HTTPError{StatusCode: response.StatusCode}
+	if response.Request != nil {
+		rv.Method = response.Request.Method
+		rv.Path = response.Request.URL.Path
+	}
+
+	return rv
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("onelogin: %s %s failed with status %d", e.Method, e.Path, e.StatusCode)
+}
+
+// GRPCStatus maps the HTTP status to the closest gRPC code, so the SDK can tell retryable failures apart.
+func (e *HTTPError) GRPCStatus() *status.Status {
+	code := codes.Unknown
+	switch {
+	case e.StatusCode == http.StatusBadRequest:
+		code = codes.InvalidArgument
+	case e.StatusCode == http.StatusUnauthorized:
+		code = codes.Unauthenticated
+	case e.StatusCode == http.StatusForbidden:
+		code = codes.PermissionDenied
+	case e.StatusCode == http.StatusNotFound:
+		code = codes.NotFound
+	case e.StatusCode == http.StatusConflict:
+		code = codes.AlreadyExists
+	case e.StatusCode == http.StatusTooManyRequests:
+		code = codes.ResourceExhausted
+	case e.StatusCode >= http.StatusInternalServerError:
+		code = codes.Unavailable
+	}
+
+	return status.New(code, e.Error())
+}
+
+// IsNotFound reports whether OneLogin answered the request with 404 Not Found.
+func IsNotFound(err error) bool {
+	var httpErr *HTTPError
+	return errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusNotFound
+}
+
+// IsUnavailable reports whether OneLogin refused the request because the API credentials may not
+// read the data or the tenant does not have the feature, i.e. with 401, 403 or 404.
+func IsUnavailable(err error) bool {
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) {
+		return false
+	}
+
+	switch httpErr.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound:
+		return true
+	default:
+		return false
+	}
+}
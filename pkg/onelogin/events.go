@@ -0,0 +1,154 @@
+package onelogin
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Event is an entry of the OneLogin event log. IDs of objects the event does not refer to are zero.
+type Event struct {
+	Id                 int       `json:"id"`
+	CreatedAt          time.Time `json:"created_at"`
+	AccountId          int       `json:"account_id"`
+	EventTypeId        int       `json:"event_type_id"`
+	UserId             int       `json:"user_id"`
+	UserName           string    `json:"user_name"`
+	ActorUserId        int       `json:"actor_user_id"`
+	ActorUserName      string    `json:"actor_user_name"`
+	ActorSystem        string    `json:"actor_system"`
+	AppId              int       `json:"app_id"`
+	AppName            string    `json:"app_name"`
+	RoleId             int       `json:"role_id"`
+	RoleName           string    `json:"role_name"`
+	GroupId            int       `json:"group_id"`
+	GroupName          string    `json:"group_name"`
+	PolicyId           int       `json:"policy_id"`
+	PolicyName         string    `json:"policy_name"`
+	DirectoryId        int       `json:"directory_id"`
+	ClientId           string    `json:"client_id"`
+	IPAddress          string    `json:"ipaddr"`
+	Notes              string    `json:"notes"`
+	CustomMessage      string    `json:"custom_message"`
+	Resolution         string    `json:"resolution"`
+	ErrorDescription   string    `json:"error_description"`
+	RiskScore          int       `json:"risk_score"`
+	RiskReasons        string    `json:"risk_reasons"`
+	BrowserFingerprint string    `json:"browser_fingerprint"`
+}
+
+type EventType struct {
+	Id          int    `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// EventTypes is the catalog of event types by ID.
+type EventTypes map[int]EventType
+
+// Name returns the name of the event type, such as USER_LOGGED_INTO_APP.
+func (t EventTypes) Name(id int) string {
+	if eventType, ok := t[id]; ok && eventType.Name != "" {
+		return eventType.Name
+	}
+
+	return fmt.Sprintf("EVENT_TYPE_%d", id)
+}
+
+// Ids returns the IDs of the event types with the given names. Unknown names are skipped.
+func (t EventTypes) Ids(names ...string) []int {
+	var rv []int
+	for _, name := range names {
+		for id, eventType := range t {
+			if eventType.Name == name {
+				rv = append(rv, id)
+			}
+		}
+	}
+
+	return rv
+}
+
+// EventIterator streams the events matching a filter page by page. The cursor can be saved
+// and passed to a new iterator to resume where the previous one stopped.
+type EventIterator struct {
+	client *Client
+	filter EventFilterVars
+	limit  int
+
+	cursor     string
+	nextCursor string
+	page       []Event
+	fetched    bool
+	event      *Event
+	err        error
+}
+
+// NewEventIterator returns an iterator over the events matching the filter, starting
+// from the saved cursor or, when it is empty, from the first matching event.
+func (c *Client) NewEventIterator(filter EventFilterVars, cursor string, limit int) *EventIterator {
+	return &EventIterator{
+		client: c,
+		filter: filter,
+		limit:  limit,
+		cursor: cursor,
+	}
+}
+
+// Next advances to the next event, fetching the next page when the current one is consumed.
+// It returns false once the events are exhausted or a request failed.
+func (it *EventIterator) Next(ctx context.Context) bool {
+	if it.err != nil {
+		return false
+	}
+
+	for len(it.page) == 0 {
+		if it.fetched {
+			if it.nextCursor == "" {
+				it.event = nil
+				return false
+			}
+			it.cursor = it.nextCursor
+		}
+
+		events, nextCursor, err := it.client.GetEvents(ctx, it.filter, PaginationVars{
+			Limit:    it.limit,
+			V1Cursor: it.cursor,
+		})
+		if err != nil {
+			it.err = err
+			it.event = nil
+			return false
+		}
+
+		it.page = events
+		it.nextCursor = nextCursor
+		it.fetched = true
+	}
+
+	it.event = &it.page[0]
+	it.page = it.page[1:]
+
+	return true
+}
+
+// Event returns the current event.
+func (it *EventIterator) Event() *Event {
+	return it.event
+}
+
+// Cursor returns the cursor to resume from. Cursors point at pages, so the events of a
+// partially consumed page are returned again on resume. Once the events are exhausted,
+// the cursor of the last page is kept, so resuming picks up events logged since.
+func (it *EventIterator) Cursor() string {
+	if it.fetched && len(it.page) == 0 && it.nextCursor != "" {
+		return it.nextCursor
+	}
+
+	return it.cursor
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *EventIterator) Err() error {
+	return it.err
+}
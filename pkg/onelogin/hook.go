@@ -0,0 +1,44 @@
+package onelogin
+
+import (
+	"encoding/json"
+)
+
+type Hook struct {
+	Id        string            `json:"id"`
+	Type      string            `json:"type"`
+	Status    string            `json:"status"`
+	Disabled  bool              `json:"disabled"`
+	Runtime   string            `json:"runtime"`
+	Timeout   int               `json:"timeout"`
+	Retries   int               `json:"retries"`
+	EnvVars   []HookEnvVar      `json:"env_vars"`
+	Packages  map[string]string `json:"packages"`
+	Function  string            `json:"function"`
+	CreatedAt string            `json:"created_at"`
+	UpdatedAt string            `json:"updated_at"`
+}
+
+// HookEnvVar is an environment variable of a Smart Hook. Only the name is kept:
+// OneLogin returns variables either as names or as objects, and any value is dropped on decode.
+type HookEnvVar struct {
+	Name string
+}
+
+func (e *HookEnvVar) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		e.Name = name
+		return nil
+	}
+
+	var envVar struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(data, &envVar); err != nil {
+		return err
+	}
+	e.Name = envVar.Name
+
+	return nil
+}
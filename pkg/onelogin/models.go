@@ -25,9 +25,17 @@ type Role struct {
 
 type UserUnderRole struct {
 	BaseResource
-	Username string `json:"username"`
-	Email    string `json:"email"`
-	Name     string `json:"name"`
+	Username string    `json:"username"`
+	Email    string    `json:"email"`
+	Name     string    `json:"name"`
+	AddedBy  *Assigner `json:"added_by,omitempty"`
+}
+
+// Assigner is the user or mapping that added a user to a role, as told by its type.
+type Assigner struct {
+	Id   int    `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type"`
 }
 
 type Credentials struct {
@@ -36,11 +44,151 @@ type Credentials struct {
 
 type App struct {
 	BaseResource
-	Name    string `json:"name"`
-	RoleIDs []int  `json:"role_ids"`
+	Name        string `json:"name"`
+	RoleIDs     []int  `json:"role_ids"`
+	PolicyId    *int   `json:"policy_id,omitempty"`
+	ConnectorId int    `json:"connector_id"`
+	IconURL     string `json:"icon_url"`
+}
+
+// CatalogConnector is the app catalog entry, e.g. "Salesforce SAML", an app is built from.
+type CatalogConnector struct {
+	BaseResource
+	Name                string `json:"name"`
+	AuthMethod          int    `json:"auth_method"`
+	AllowsNewParameters bool   `json:"allows_new_parameters"`
+}
+
+type AppDetail struct {
+	App
+	Parameters map[string]AppParameter `json:"parameters"`
+}
+
+type AppParameter struct {
+	Id                        int    `json:"id"`
+	Label                     string `json:"label"`
+	UserAttributeMappings     string `json:"user_attribute_mappings"`
+	UserAttributeMacros       string `json:"user_attribute_macros"`
+	Values                    string `json:"values"`
+	DefaultValues             string `json:"default_values"`
+	ProvisionedEntitlements   bool   `json:"provisioned_entitlements"`
+	AttributesTransformations string `json:"attributes_transformations"`
 }
 
 type Group struct {
 	BaseResource
-	Name string `json:"name"`
+	Name     string `json:"name"`
+	PolicyId *int   `json:"policy_id,omitempty"`
+}
+
+type Privilege struct {
+	Id          string            `json:"id"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Privilege   PrivilegeDocument `json:"privilege"`
+}
+
+type PrivilegeDocument struct {
+	Version   string               `json:"Version"`
+	Statement []PrivilegeStatement `json:"Statement"`
+}
+
+type PrivilegeStatement struct {
+	Effect string   `json:"Effect"`
+	Action []string `json:"Action"`
+	Scope  []string `json:"Scope"`
+}
+
+type Mapping struct {
+	BaseResource
+	Name       string          `json:"name"`
+	Match      string          `json:"match"`
+	Enabled    bool            `json:"enabled"`
+	Position   *int            `json:"position"`
+	Conditions []RuleCondition `json:"conditions"`
+	Actions    []RuleAction    `json:"actions"`
+}
+
+type AppRule struct {
+	BaseResource
+	Name       string          `json:"name"`
+	Match      string          `json:"match"`
+	Enabled    bool            `json:"enabled"`
+	Position   *int            `json:"position"`
+	Conditions []RuleCondition `json:"conditions"`
+	Actions    []RuleAction    `json:"actions"`
+}
+
+// RuleCondition and RuleAction are shared by user mappings and app rules.
+type RuleCondition struct {
+	Source   string `json:"source"`
+	Operator string `json:"operator"`
+	Value    string `json:"value"`
+}
+
+type RuleAction struct {
+	Action string   `json:"action"`
+	Value  []string `json:"value"`
+}
+
+type APIAuthorization struct {
+	BaseResource
+	Name          string                        `json:"name"`
+	Description   string                        `json:"description"`
+	Configuration APIAuthorizationConfiguration `json:"configuration"`
+}
+
+type APIAuthorizationConfiguration struct {
+	ResourceIdentifier            string   `json:"resource_identifier"`
+	Audiences                     []string `json:"audiences"`
+	AccessTokenExpirationMinutes  int      `json:"access_token_expiration_minutes"`
+	RefreshTokenExpirationMinutes int      `json:"refresh_token_expiration_minutes"`
+}
+
+type APIScope struct {
+	BaseResource
+	Value       string `json:"value"`
+	Description string `json:"description"`
+}
+
+type APIClient struct {
+	AppId  int        `json:"app_id"`
+	Name   string     `json:"name"`
+	Scopes []APIScope `json:"scopes"`
+}
+
+type TrustedIdp struct {
+	BaseResource
+	Name        string `json:"name"`
+	Enabled     bool   `json:"enabled"`
+	Issuer      string `json:"issuer"`
+	SSOEndpoint string `json:"sso_endpoint"`
+	Certificate string `json:"certificate"`
+}
+
+type SelfRegistrationProfile struct {
+	BaseResource
+	Name            string `json:"name"`
+	URL             string `json:"url"`
+	Enabled         bool   `json:"enabled"`
+	Moderated       bool   `json:"moderated"`
+	DefaultRoleId   *int   `json:"default_role_id"`
+	DefaultGroupId  *int   `json:"default_group_id"`
+	DomainWhitelist string `json:"domain_whitelist"`
+	DomainBlacklist string `json:"domain_blacklist"`
+}
+
+type Brand struct {
+	BaseResource
+	Name                 string `json:"name"`
+	Enabled              bool   `json:"enabled"`
+	CustomDomain         string `json:"custom_domain"`
+	CustomSupportEnabled bool   `json:"custom_support_enabled"`
+	HideOneLoginFooter   bool   `json:"hide_onelogin_footer"`
+}
+
+type BrandTemplate struct {
+	BaseResource
+	Type   string `json:"type"`
+	Locale string `json:"locale"`
 }
@@ -0,0 +1,60 @@
+package onelogin
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+const (
+	PolicyTypeUser = "user"
+	PolicyTypeApp  = "app"
+)
+
+// defaultPolicyName is the name OneLogin gives the account default user policy.
+const defaultPolicyName = "Default policy"
+
+// Policy is a user or app security policy. Its settings vary with the policy type,
+// so everything besides the identifying fields is kept as returned in Settings.
+type Policy struct {
+	BaseResource
+	Name     string
+	Type     string
+	Settings map[string]interface{}
+}
+
+func (p *Policy) UnmarshalJSON(data []byte) error {
+	var policy struct {
+		BaseResource
+		Name string `json:"name"`
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return err
+	}
+
+	settings := make(map[string]interface{})
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return err
+	}
+	for _, key := range []string{"id", "name", "type"} {
+		delete(settings, key)
+	}
+
+	p.BaseResource = policy.BaseResource
+	p.Name = policy.Name
+	p.Type = policy.Type
+	p.Settings = settings
+
+	return nil
+}
+
+// IsDefault reports whether the policy is the account default, which governs the users with no policy
+// of their own or of their group. The policy is flagged as default when OneLogin returns the flag, and
+// is otherwise recognized by its name.
+func (p *Policy) IsDefault() bool {
+	if isDefault, ok := p.Settings["default"].(bool); ok {
+		return isDefault
+	}
+
+	return strings.EqualFold(p.Name, defaultPolicyName)
+}
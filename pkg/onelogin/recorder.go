@@ -0,0 +1,39 @@
+package onelogin
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+)
+
+const requestIdHeader = "X-Request-Id"
+
+type recorderKey struct{}
+
+// ResponseRecorder captures details of the last write request made with a context, for audit purposes.
+type ResponseRecorder struct {
+	PayloadSHA256 string
+	StatusCode    int
+	RequestId     string
+}
+
+// WithResponseRecorder returns a context that makes the client fill in the recorder on every write request.
+func WithResponseRecorder(ctx context.Context, recorder *ResponseRecorder) context.Context {
+	return context.WithValue(ctx, recorderKey{}, recorder)
+}
+
+func recordResponse(ctx context.Context, payload []byte, response *http.Response) {
+	recorder, ok := ctx.Value(recorderKey{}).(*ResponseRecorder)
+	if !ok || recorder == nil {
+		return
+	}
+
+	recorder.PayloadSHA256 = ""
+	if len(payload) != 0 {
+		sum := sha256.Sum256(payload)
+		recorder.PayloadSHA256 = hex.EncodeToString(sum[:])
+	}
+	recorder.StatusCode = response.StatusCode
+	recorder.RequestId = response.Header.Get(requestIdHeader)
+}
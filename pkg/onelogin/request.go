@@ -3,7 +3,9 @@ package onelogin
 import (
 	"fmt"
 	"net/url"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // Query parameters types.
@@ -70,6 +72,68 @@ func prepareGroupUsersFilters(groupId string) *FilterVars {
 	}
 }
 
+// PolicyFilterVars selects user or app policies.
+type PolicyFilterVars struct {
+	Type string
+}
+
+func (pF *PolicyFilterVars) setup(params *url.Values) {
+	if pF.Type != "" {
+		params.Set("type", pF.Type)
+	}
+}
+
+// AppFilterVars selects the apps built from a catalog connector.
+type AppFilterVars struct {
+	ConnectorId string
+}
+
+func (aF *AppFilterVars) setup(params *url.Values) {
+	if aF.ConnectorId != "" {
+		params.Set("connector_id", aF.ConnectorId)
+	}
+}
+
+// EventFilterVars selects the events of the event log. Zero values do not filter.
+type EventFilterVars struct {
+	Since        time.Time
+	Until        time.Time
+	EventTypeIds []int
+	UserId       string
+	ClientId     string
+	DirectoryId  string
+}
+
+func (eF *EventFilterVars) setup(params *url.Values) {
+	if !eF.Since.IsZero() {
+		params.Set("since", eF.Since.UTC().Format(time.RFC3339))
+	}
+
+	if !eF.Until.IsZero() {
+		params.Set("until", eF.Until.UTC().Format(time.RFC3339))
+	}
+
+	if len(eF.EventTypeIds) != 0 {
+		ids := make([]string, 0, len(eF.EventTypeIds))
+		for _, id := range eF.EventTypeIds {
+			ids = append(ids, strconv.Itoa(id))
+		}
+		params.Set("event_type_id", strings.Join(ids, ","))
+	}
+
+	if eF.UserId != "" {
+		params.Set("user_id", eF.UserId)
+	}
+
+	if eF.ClientId != "" {
+		params.Set("client_id", eF.ClientId)
+	}
+
+	if eF.DirectoryId != "" {
+		params.Set("directory_id", eF.DirectoryId)
+	}
+}
+
 // Request Body types.
 type GrantBody struct {
 	GrantType string `json:"grant_type"`
@@ -80,3 +144,19 @@ func NewCredentialsGrant() *GrantBody {
 		GrantType: "client_credentials",
 	}
 }
+
+// RoleBody is used both to create a role and to rename it. Apps, users and admins
+// are only honoured by OneLogin when the role is created.
+type RoleBody struct {
+	Name   string `json:"name"`
+	Apps   []int  `json:"apps,omitempty"`
+	Users  []int  `json:"users,omitempty"`
+	Admins []int  `json:"admins,omitempty"`
+}
+
+// APIClientBody adds a client app to an API authorization server or, without the app ID,
+// replaces the scopes granted to it.
+type APIClientBody struct {
+	AppId  int   `json:"app_id,omitempty"`
+	Scopes []int `json:"scopes"`
+}